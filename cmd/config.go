@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/codek7-services/codek7-tui/internal/config"
+)
+
+const configUsage = `usage: codek7-tui config <show|validate|edit> [flags]
+
+  show      print every setting with the layer it came from
+  validate  check the configuration and exit non-zero on errors
+  edit      open the config file in $EDITOR, creating it if needed`
+
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "show":
+		cfg, ok := loadForCommand(args[1:])
+		if !ok {
+			return 1
+		}
+		showConfig(cfg)
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "\nconfiguration has errors:\n%v\n", err)
+			return 1
+		}
+	case "validate":
+		cfg, ok := loadForCommand(args[1:])
+		if !ok {
+			return 1
+		}
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "configuration has errors:\n%v\n", err)
+			return 1
+		}
+		fmt.Println("configuration is valid")
+	case "edit":
+		return editConfig(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+	return 0
+}
+
+func loadForCommand(args []string) (*config.Config, bool) {
+	cfg, _, err := config.Load("codek7-tui config", args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, false
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuration error: %v\n", err)
+		return nil, false
+	}
+	return cfg, true
+}
+
+func showConfig(cfg *config.Config) {
+	if cfg.Path() != "" {
+		fmt.Printf("config file: %s\n\n", cfg.Path())
+	} else {
+		fmt.Printf("config file: none (looked for %s)\n\n", config.DefaultPath())
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, s := range cfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+	w.Flush()
+}
+
+func editConfig(args []string) int {
+	fs := flag.NewFlagSet("codek7-tui config edit", flag.ContinueOnError)
+	path := fs.String("config", "", "config file to edit")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *path == "" {
+		*path = os.Getenv("CODEK7_CONFIG")
+	}
+	if *path == "" {
+		*path = config.DefaultPath()
+	}
+
+	if _, err := os.Stat(*path); errors.Is(err, os.ErrNotExist) {
+		if err := config.WriteDefault(*path); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot create %s: %v\n", *path, err)
+			return 1
+		}
+		fmt.Printf("created %s with default settings\n", *path)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("sh", "-c", editor+` "$0"`, *path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "editor failed: %v\n", err)
+		return 1
+	}
+
+	cfg, ok := loadForCommand([]string{"--config", *path})
+	if !ok {
+		return 1
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "configuration has errors:\n%v\n", err)
+		return 1
+	}
+	fmt.Println("configuration is valid")
+	return 0
+}
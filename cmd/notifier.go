@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/codek7-services/codek7-tui/internal"
+)
+
+const notifierUsage = `usage: codek7-tui notifier [flags]
+
+Runs a local stand-in notifier that supports catch-up. Publish events with
+  curl -d '{"type":"video_ready","video_id":"abc"}' http://localhost:8080/events/<user_id>
+and point the TUI at it with WS_ADDR=ws://localhost:8080.
+
+flags:`
+
+func runNotifier(args []string) int {
+	fs := flag.NewFlagSet("codek7-tui notifier", flag.ContinueOnError)
+	listen := fs.String("listen", "localhost:8080", "address to listen on")
+	path := fs.String("path", "/ws/{user_id}", "WebSocket path; must contain {user_id}")
+	keep := fs.Int("keep", 1000, "events kept per user for catch-up")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), notifierUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if !strings.HasPrefix(*path, "/") || !strings.Contains(*path, "{user_id}") {
+		fmt.Fprintln(os.Stderr, "-path must start with / and contain {user_id}")
+		return 2
+	}
+	if *keep < 1 {
+		fmt.Fprintln(os.Stderr, "-keep must be at least 1")
+		return 2
+	}
+
+	log.Printf("Stand-in notifier listening on %s (WebSocket %s, publish POST /events/{user_id})", *listen, *path)
+	if err := http.ListenAndServe(*listen, internal.NewStandInNotifier(*path, *keep)); err != nil {
+		fmt.Fprintf(os.Stderr, "notifier failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -1,16 +1,94 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
 
+	"github.com/codek7-services/codek7-tui/internal/config"
 	"github.com/codek7-services/codek7-tui/internal/tui"
 	"github.com/joho/godotenv"
 )
 
 func main() {
 	_ = godotenv.Load()
-	app := tui.NewApp()
-	if err := app.Run(); err != nil {
-		log.Fatalf("TUI failed: %v", err)
+
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "config":
+			os.Exit(runConfig(args[1:]))
+		case "notifier":
+			os.Exit(runNotifier(args[1:]))
+		case "watch":
+			os.Exit(runWatch(args[1:]))
+		}
+	}
+	os.Exit(runTUI(args))
+}
+
+// runTUI returns the exit code instead of exiting, so the log file is
+// closed and errors reach the terminal once it is restored
+func runTUI(args []string) int {
+	cfg, _, err := config.Load("codek7-tui", args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 2
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		return 2
+	}
+
+	closeLog, err := setupLogging(cfg.Log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open log file: %v\n", err)
+		return 1
+	}
+
+	app := tui.NewApp(cfg)
+	err = app.Run()
+	closeLog()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "TUI failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// setupLogging sends log output to the configured file so it does not
+// corrupt the terminal while the TUI is running
+func setupLogging(cfg config.LogConfig) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.File), 0o700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
 	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("log level: %w", err)
+	}
+	if cfg.Debug {
+		level = slog.LevelDebug
+	}
+	prev, flags := slog.Default(), log.Flags()
+	slog.SetDefault(slog.New(slog.NewTextHandler(file, &slog.HandlerOptions{Level: level})))
+
+	// Undo SetDefault, which also routed the log package to the file
+	return func() {
+		slog.SetDefault(prev)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+		file.Close()
+	}, nil
 }
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/codek7-services/codek7-tui/internal/config"
+)
+
+const watchUsage = `usage: codek7-tui watch [flags] <user_id>
+
+Prints the user's notifications as they arrive, reconnecting when the
+connection drops. With --exit-on it waits for a condition, for scripts:
+
+  codek7-tui watch --exit-on video_ready:abc123 --timeout 30m user42
+
+exit codes:
+  0  an --exit-on condition was met, or stopped by a signal without one
+  1  a video named in --exit-on failed processing
+  2  usage or configuration error
+  3  --timeout passed first
+  4  gave up connecting after --max-retries
+  130  stopped by a signal while waiting for --exit-on
+
+flags:`
+
+// Watch exit codes
+const (
+	exitMet         = 0
+	exitFailed      = 1
+	exitUsage       = 2
+	exitTimeout     = 3
+	exitUnreachable = 4
+	exitInterrupted = 130
+)
+
+// listFlag collects a repeatable, comma separated flag
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// exitCondition ends the watch when an event of the type arrives, for the
+// video if one is named
+type exitCondition struct {
+	kind    internal.EventKind
+	videoID string
+}
+
+// parseExitCondition parses TYPE or TYPE:VIDEO_ID
+func parseExitCondition(s string) (exitCondition, error) {
+	kind, videoID, _ := strings.Cut(s, ":")
+	if kind == "" {
+		return exitCondition{}, fmt.Errorf("--exit-on %q: want TYPE or TYPE:VIDEO_ID", s)
+	}
+	if !internal.EventKind(kind).Known() {
+		return exitCondition{}, fmt.Errorf("--exit-on %q: unknown event type %q", s, kind)
+	}
+	return exitCondition{kind: internal.EventKind(kind), videoID: videoID}, nil
+}
+
+func (c exitCondition) met(e internal.Event) bool {
+	return e.Kind == c.kind && (c.videoID == "" || c.videoID == e.VideoID)
+}
+
+// failed reports whether the event means the condition can no longer be met
+func (c exitCondition) failed(e internal.Event) bool {
+	return c.videoID != "" && c.videoID == e.VideoID &&
+		e.Kind == internal.KindProcessingFailed && c.kind != internal.KindProcessingFailed
+}
+
+// watcher prints events and decides when the watch is over. Its methods
+// run on the notifier client's goroutine.
+type watcher struct {
+	json       bool
+	types      []string
+	conditions []exitCondition
+	maxRetries int
+
+	cursor internal.Cursor
+	seen   *internal.RecentIDs
+	exit   chan int
+}
+
+func runWatch(args []string) int {
+	w := &watcher{seen: internal.NewRecentIDs(1000), exit: make(chan int, 1)}
+	var types, exitOn listFlag
+	var since string
+	var timeout time.Duration
+	cfg, rest, err := config.Load("codek7-tui watch", args, func(fs *flag.FlagSet) {
+		fs.BoolVar(&w.json, "json", false, "print each event as a JSON line")
+		fs.Var(&types, "type", "only print these event types (repeatable, comma separated)")
+		fs.Var(&exitOn, "exit-on", "exit once an event matches TYPE or TYPE:VIDEO_ID (repeatable)")
+		fs.DurationVar(&timeout, "timeout", 0, "give up after this long (0 waits forever)")
+		fs.IntVar(&w.maxRetries, "max-retries", 0, "give up after this many failed connects in a row (0 retries forever)")
+		fs.StringVar(&since, "since", "", "replay events after this event ID or RFC 3339 time, if the notifier supports it")
+		fs.Usage = func() {
+			fmt.Fprintln(fs.Output(), watchUsage)
+			fs.PrintDefaults()
+		}
+	})
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuration error: %v\n", err)
+		return exitUsage
+	}
+	if len(rest) != 1 || rest[0] == "" {
+		fmt.Fprintln(os.Stderr, "watch needs exactly one user ID; see codek7-tui watch --help")
+		return exitUsage
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "configuration has errors:\n%v\n", err)
+		return exitUsage
+	}
+	w.types = types
+	for _, s := range exitOn {
+		c, err := parseExitCondition(s)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		w.conditions = append(w.conditions, c)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, since); err == nil {
+		w.cursor.At = t
+	} else {
+		w.cursor.EventID = since
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	client := internal.NewNotifierClient(cfg.NotifierOptions(), internal.NotifierHandlers{
+		OnState:   w.onState,
+		OnMessage: w.onMessage,
+		Since:     w.since,
+	})
+	client.Start(rest[0])
+	defer client.Stop()
+
+	select {
+	case code := <-w.exit:
+		return code
+	case <-expired:
+		fmt.Fprintf(os.Stderr, "timed out after %s\n", timeout)
+		return exitTimeout
+	case <-ctx.Done():
+		if len(w.conditions) > 0 {
+			return exitInterrupted
+		}
+		return 0
+	}
+}
+
+// since resumes after the last event printed, so a reconnect asks for what
+// was missed in between
+func (w *watcher) since() string {
+	return w.cursor.Since()
+}
+
+func (w *watcher) onState(status internal.ConnStatus) {
+	switch status.State {
+	case internal.StateConnected:
+		fmt.Fprintln(os.Stderr, "connected")
+	case internal.StateBackoff:
+		if w.maxRetries > 0 && status.Attempt >= w.maxRetries {
+			fmt.Fprintf(os.Stderr, "giving up after %d failed attempts: %v\n", status.Attempt, status.Err)
+			w.finish(exitUnreachable)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "connection failed (attempt %d): %v; retrying in %s\n",
+			status.Attempt, status.Err, time.Until(status.RetryAt).Round(time.Second))
+	}
+}
+
+func (w *watcher) onMessage(data []byte) {
+	e, err := internal.DecodeEvent(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "malformed notification: %v\n", err)
+		return
+	}
+	if w.seen.Seen(e.ID) {
+		return // delivered again in a replay
+	}
+	w.cursor.Advance(e)
+
+	if len(w.types) == 0 || slices.Contains(w.types, string(e.Kind)) {
+		w.print(e)
+	}
+	for _, c := range w.conditions {
+		switch {
+		case c.met(e):
+			w.finish(exitMet)
+		case c.failed(e):
+			fmt.Fprintf(os.Stderr, "video %s failed processing\n", e.VideoID)
+			w.finish(exitFailed)
+		}
+	}
+}
+
+func (w *watcher) print(e internal.Event) {
+	if w.json {
+		line, err := json.Marshal(e)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "encoding event %s: %v\n", e.ID, err)
+			return
+		}
+		fmt.Println(string(line))
+		return
+	}
+
+	at := e.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	line := at.Local().Format("2006-01-02 15:04:05") + " " + string(e.Kind)
+	if e.VideoID != "" {
+		line += " [" + e.VideoID + "]"
+	}
+	line += " " + e.Summary()
+	if e.Replayed() {
+		line += " (missed while offline)"
+	}
+	fmt.Println(line)
+}
+
+// finish reports the exit code; the first one wins
+func (w *watcher) finish(code int) {
+	select {
+	case w.exit <- code:
+	default:
+	}
+}
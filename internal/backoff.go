@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes exponential retry delays with jitter
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Delay returns the wait before retry number attempt (starting at 1). The
+// delay doubles per attempt up to Max, and half of it is randomised so
+// clients do not reconnect in lockstep.
+func (b Backoff) Delay(attempt int) time.Duration {
+	if b.Min <= 0 {
+		b.Min = time.Second
+	}
+	if b.Max < b.Min {
+		b.Max = b.Min
+	}
+
+	d := b.Min
+	for i := 1; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+
+	half := d / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cursor is the last event a client saw. It is sent as the since
+// parameter on connect, so the notifier can replay what was missed.
+type Cursor struct {
+	EventID string    `json:"event_id,omitempty"`
+	At      time.Time `json:"at,omitempty"`
+}
+
+// IsZero reports whether no event has been seen
+func (c Cursor) IsZero() bool {
+	return c.EventID == "" && c.At.IsZero()
+}
+
+// Since renders the cursor as the since parameter: the event ID when there
+// is one, otherwise the event time
+func (c Cursor) Since() string {
+	if c.EventID != "" {
+		return c.EventID
+	}
+	if !c.At.IsZero() {
+		return c.At.UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// Advance moves the cursor to the event and reports whether it moved.
+// Events older than the cursor, such as replays, leave it where it is.
+func (c *Cursor) Advance(e Event) bool {
+	if e.ID == "" && e.Timestamp.IsZero() {
+		return false
+	}
+	if !e.Timestamp.IsZero() && e.Timestamp.Before(c.At) {
+		return false
+	}
+	c.EventID = e.ID
+	if !e.Timestamp.IsZero() {
+		c.At = e.Timestamp
+	}
+	return true
+}
+
+// Replayed reports whether the notifier resent the event because it was
+// sent while the client was away
+func (e Event) Replayed() bool {
+	var replayed bool
+	return json.Unmarshal(e.Extra["replayed"], &replayed) == nil && replayed
+}
+
+// RecentIDs remembers the last event IDs seen, so events delivered twice,
+// live and again in a replay, are dropped. It is not safe for concurrent
+// use.
+type RecentIDs struct {
+	size  int
+	ids   map[string]bool
+	order []string
+}
+
+func NewRecentIDs(size int) *RecentIDs {
+	return &RecentIDs{size: size, ids: make(map[string]bool, size)}
+}
+
+// Seen records the ID and reports whether it was already there. Events
+// without an ID are never duplicates.
+func (r *RecentIDs) Seen(id string) bool {
+	if id == "" {
+		return false
+	}
+	if r.ids[id] {
+		return true
+	}
+	r.ids[id] = true
+	r.order = append(r.order, id)
+	if len(r.order) > r.size {
+		delete(r.ids, r.order[0])
+		r.order = r.order[1:]
+	}
+	return false
+}
+
+// CursorStore keeps each user's cursor in a small JSON file. Without a
+// directory cursors live only as long as the process.
+type CursorStore struct {
+	Dir string
+}
+
+func (s CursorStore) path(userID string) string {
+	return filepath.Join(s.Dir, url.PathEscape(userID)+".json")
+}
+
+// Load returns the user's cursor; zero if none was saved
+func (s CursorStore) Load(userID string) (Cursor, error) {
+	var c Cursor
+	if s.Dir == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(s.path(userID))
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("cursor %s: %v", s.path(userID), err)
+	}
+	return c, nil
+}
+
+// Save writes the user's cursor
+func (s CursorStore) Save(userID string, c Cursor) error {
+	if s.Dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+
+	// Write then rename so a crash never leaves a torn cursor
+	tmp, err := os.CreateTemp(s.Dir, ".cursor-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(userID))
+}
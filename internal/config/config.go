@@ -0,0 +1,663 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Config is the typed application configuration. Values are layered from
+// defaults, the config file, environment variables and command-line flags,
+// in that order of precedence.
+type Config struct {
+	GRPC          GRPCConfig         `yaml:"grpc"`
+	Notifier      NotifierConfig     `yaml:"notifier"`
+	Timeouts      TimeoutConfig      `yaml:"timeouts"`
+	Transfer      TransferConfig     `yaml:"transfer"`
+	Session       SessionConfig      `yaml:"session"`
+	Notifications NotificationConfig `yaml:"notifications"`
+	Keys          KeyConfig          `yaml:"keys"`
+	Theme         ThemeConfig        `yaml:"theme"`
+	Log           LogConfig          `yaml:"log"`
+	Cache         CacheConfig        `yaml:"cache"`
+	Proxy         ProxyConfig        `yaml:"proxy"`
+	Sinks         []SinkConfig       `yaml:"sinks" usage:"forward notifications to webhooks and shell commands"`
+
+	path    string
+	sources map[string]Source
+}
+
+type GRPCConfig struct {
+	Addr      string      `yaml:"addr" env:"GRPC_ADDR" flag:"grpc-addr" usage:"gRPC repo service address (host:port or unix:///path)"`
+	TLS       TLSConfig   `yaml:"tls" env:"GRPC_"`
+	Preflight bool        `yaml:"preflight" env:"GRPC_PREFLIGHT" flag:"preflight" usage:"wait for the server to be ready before showing the login form"`
+	Retry     RetryConfig `yaml:"retry"`
+}
+
+// RetryConfig controls automatic retries of read-only RPCs
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts" env:"GRPC_RETRY_MAX_ATTEMPTS" usage:"attempts per read-only RPC, including the first (1 disables retries, max 5)"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" env:"GRPC_RETRY_INITIAL_BACKOFF" usage:"delay before the first retry"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" env:"GRPC_RETRY_MAX_BACKOFF" usage:"upper bound for the retry delay"`
+	Codes          []string      `yaml:"codes" env:"GRPC_RETRY_CODES" usage:"status codes that are retried"`
+}
+
+type NotifierConfig struct {
+	Addr         string            `yaml:"addr" env:"WS_ADDR" flag:"ws-addr" usage:"notifier base URL (ws://, wss:// or unix:///path)"`
+	Path         string            `yaml:"path" env:"WS_PATH" flag:"ws-path" usage:"notifier path template, {user_id} is replaced"`
+	Headers      map[string]string `yaml:"headers" env:"WS_HEADERS" usage:"extra handshake headers (env format: Name=Value;Name=Value)"`
+	Subprotocols []string          `yaml:"subprotocols" env:"WS_SUBPROTOCOLS" flag:"ws-subprotocols" usage:"WebSocket subprotocols to offer (env and flag format: comma separated)"`
+	// TLS falls back to the gRPC settings while every notifier TLS value is
+	// empty, as in the file config edit writes
+	TLS TLSConfig `yaml:"tls" env:"WS_" flag:"ws-"`
+
+	ReconnectMin time.Duration `yaml:"reconnect_min" env:"WS_RECONNECT_MIN" usage:"first reconnect delay after the connection drops"`
+	ReconnectMax time.Duration `yaml:"reconnect_max" env:"WS_RECONNECT_MAX" usage:"upper bound for the reconnect delay"`
+	PingInterval time.Duration `yaml:"ping_interval" env:"WS_PING_INTERVAL" usage:"heartbeat ping interval (0 disables heartbeats)"`
+	PongTimeout  time.Duration `yaml:"pong_timeout" env:"WS_PONG_TIMEOUT" usage:"how long to wait for a pong before the connection is stale"`
+
+	CatchUp   bool   `yaml:"catch_up" env:"WS_CATCH_UP" usage:"ask the notifier on connect for events sent while the client was away"`
+	CursorDir string `yaml:"cursor_dir" env:"WS_CURSOR_DIR" usage:"where the last event seen is kept per user (empty keeps it in memory)"`
+}
+
+type TLSConfig struct {
+	Insecure   bool   `yaml:"insecure" env:"INSECURE" flag:"insecure" usage:"connect without TLS"`
+	CA         string `yaml:"ca" env:"TLS_CA" flag:"tls-ca" usage:"PEM CA bundle used to verify the server"`
+	ServerName string `yaml:"server_name" env:"TLS_SERVER_NAME" flag:"tls-server-name" usage:"override the server name checked against the certificate"`
+	Cert       string `yaml:"cert" env:"TLS_CERT" flag:"tls-cert" usage:"client certificate for mutual TLS"`
+	Key        string `yaml:"key" env:"TLS_KEY" flag:"tls-key" usage:"client key for mutual TLS"`
+	Pin        string `yaml:"pin" env:"TLS_PIN" flag:"tls-pin" usage:"base64 SHA-256 SPKI pin of the server certificate"`
+}
+
+// TimeoutConfig holds the connect timeout and a deadline per RPC type; a
+// zero RPC deadline means the call is only bounded by its view
+type TimeoutConfig struct {
+	Connect      time.Duration `yaml:"connect" env:"CONNECT_TIMEOUT" flag:"connect-timeout" usage:"timeout for establishing backend connections"`
+	Login        time.Duration `yaml:"login" env:"LOGIN_TIMEOUT" usage:"deadline for the login RPC"`
+	Register     time.Duration `yaml:"register" env:"REGISTER_TIMEOUT" usage:"deadline for the register RPC"`
+	ListVideos   time.Duration `yaml:"list_videos" env:"LIST_VIDEOS_TIMEOUT" usage:"deadline for loading the video library"`
+	RecentVideos time.Duration `yaml:"recent_videos" env:"RECENT_VIDEOS_TIMEOUT" usage:"deadline for loading recent videos"`
+	GetVideo     time.Duration `yaml:"get_video" env:"GET_VIDEO_TIMEOUT" usage:"deadline for loading a single video"`
+	Upload       time.Duration `yaml:"upload" env:"UPLOAD_TIMEOUT" usage:"deadline for a whole upload stream"`
+}
+
+type TransferConfig struct {
+	MaxUploadMB int    `yaml:"max_upload_mb" env:"MAX_UPLOAD_MB" flag:"max-upload-mb" usage:"largest file accepted for upload, in MB"`
+	ChunkSizeKB int    `yaml:"chunk_size_kb" env:"UPLOAD_CHUNK_KB" usage:"upload chunk size, in KB"`
+	OutboxDir   string `yaml:"outbox_dir" env:"OUTBOX_DIR" usage:"where uploads made while offline are queued (empty disables the outbox)"`
+}
+
+type SessionConfig struct {
+	IdleLock time.Duration `yaml:"idle_lock" env:"IDLE_LOCK_TIMEOUT" flag:"idle-lock" usage:"lock the TUI after this long without input (0 disables)"`
+}
+
+// NotificationConfig controls how incoming notifications are surfaced
+type NotificationConfig struct {
+	ToastTimeout time.Duration `yaml:"toast_timeout" env:"TOAST_TIMEOUT" usage:"how long notification toasts stay on screen (0 disables toasts)"`
+	MaxToasts    int           `yaml:"max_toasts" env:"MAX_TOASTS" usage:"most toasts stacked at once"`
+	RefreshDelay time.Duration `yaml:"refresh_delay" env:"AUTO_REFRESH_DELAY" usage:"reload videos this long after notifications say they changed (0 disables)"`
+	DeliveryLog  string        `yaml:"delivery_log" env:"SINK_DELIVERY_LOG" usage:"JSON-lines log of notifications forwarded to sinks (empty disables the file)"`
+
+	Rules      []RuleConfig `yaml:"rules" usage:"mute, prioritise or auto-dismiss matching notifications; the first match wins"`
+	QuietHours string       `yaml:"quiet_hours" env:"QUIET_HOURS" usage:"daily HH:MM-HH:MM period when only priority notifications interrupt"`
+
+	Desktop      string   `yaml:"desktop" env:"DESKTOP_NOTIFY" usage:"terminal desktop notifications: off, auto, osc9, osc777 or bell"`
+	DesktopTypes []string `yaml:"desktop_types" env:"DESKTOP_NOTIFY_TYPES" usage:"notification types raised on the desktop; priority ones always are"`
+
+	HistoryDir       string        `yaml:"history_dir" env:"NOTIFY_HISTORY_DIR" usage:"where each user's notification history is kept (empty disables history)"`
+	HistoryRetention time.Duration `yaml:"history_retention" env:"NOTIFY_HISTORY_RETENTION" usage:"drop history entries older than this (0 keeps them forever)"`
+	HistoryMax       int           `yaml:"history_max" env:"NOTIFY_HISTORY_MAX" usage:"most history entries kept per user (0 for no limit)"`
+}
+
+// KeyConfig maps dashboard actions to keys: a single character or
+// ctrl+<letter>. Lock and Dismiss work in every view, so they must be
+// ctrl+<letter>.
+type KeyConfig struct {
+	Upload        string `yaml:"upload"`
+	Videos        string `yaml:"videos"`
+	Notifications string `yaml:"notifications"`
+	Recent        string `yaml:"recent"`
+	Refresh       string `yaml:"refresh"`
+	WebSocket     string `yaml:"websocket"`
+	MainMenu      string `yaml:"main_menu"`
+	Logout        string `yaml:"logout"`
+	Quit          string `yaml:"quit"`
+	Lock          string `yaml:"lock"`
+	Reconnect     string `yaml:"reconnect"`
+	Outbox        string `yaml:"outbox"`
+	Dismiss       string `yaml:"dismiss"` // clears sticky toasts from any view
+}
+
+// ThemeConfig holds color names understood by tcell (e.g. "yellow", "#ff8800")
+type ThemeConfig struct {
+	Background string `yaml:"background"`
+	Text       string `yaml:"text"`
+	Border     string `yaml:"border"`
+	Title      string `yaml:"title"`
+	Header     string `yaml:"header"`
+}
+
+// ProxyConfig routes gRPC and WebSocket traffic through a proxy. Without a
+// URL, HTTPS_PROXY, ALL_PROXY and NO_PROXY from the environment are used.
+type ProxyConfig struct {
+	URL     string `yaml:"url" env:"CODEK7_PROXY" flag:"proxy" usage:"proxy URL: http://, https://, socks5:// or socks5h://, optionally with user:password@ (\"direct\" ignores HTTPS_PROXY/ALL_PROXY)"`
+	NoProxy string `yaml:"no_proxy" env:"CODEK7_NO_PROXY" flag:"no-proxy" usage:"comma-separated hosts, domains and CIDRs reached directly"`
+}
+
+// RuleConfig matches notifications by type, message and video; every
+// condition that is set must match
+type RuleConfig struct {
+	Name    string   `yaml:"name"`
+	Types   []string `yaml:"types,omitempty"`
+	Message string   `yaml:"message,omitempty"` // regular expression
+	Video   string   `yaml:"video,omitempty"`
+	Action  string   `yaml:"action"` // mute, priority or dismiss
+}
+
+// String describes the rule in one line
+func (r RuleConfig) String() string {
+	var conds []string
+	if len(r.Types) > 0 {
+		conds = append(conds, "type "+strings.Join(r.Types, "|"))
+	}
+	if r.Message != "" {
+		conds = append(conds, "message ~ /"+r.Message+"/")
+	}
+	if r.Video != "" {
+		conds = append(conds, "video "+r.Video)
+	}
+	if len(conds) == 0 {
+		conds = append(conds, "everything")
+	}
+	return fmt.Sprintf("%s: %s %s", r.Name, r.Action, strings.Join(conds, ", "))
+}
+
+// Rule compiles the rule
+func (r RuleConfig) Rule() (internal.Rule, error) {
+	rule := internal.Rule{
+		Name:    r.Name,
+		Message: r.Message,
+		VideoID: r.Video,
+		Action:  internal.RuleAction(r.Action),
+	}
+	for _, kind := range r.Types {
+		rule.Types = append(rule.Types, internal.EventKind(kind))
+	}
+	return rule, rule.Compile()
+}
+
+// SinkConfig forwards notifications to a webhook or a shell command. Sinks
+// are set in the config file only.
+type SinkConfig struct {
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"`    // webhook or shell
+	URL     string            `yaml:"url"`     // webhook: where the event JSON is POSTed
+	Headers map[string]string `yaml:"headers"` // webhook: extra request headers
+	Command string            `yaml:"command"` // shell: run with sh -c, event in CODEK7_* variables
+	Types   []string          `yaml:"types"`   // event types to forward; empty forwards all
+	Retries int               `yaml:"retries"`
+	Timeout time.Duration     `yaml:"timeout"` // per attempt; 0 means 10s
+}
+
+// String describes the sink without its headers, which may hold secrets
+func (s SinkConfig) String() string {
+	target := redactURL(s.URL)
+	if s.Type == internal.SinkShell {
+		target = s.Command
+	}
+	return fmt.Sprintf("%s (%s %s)", s.Name, s.Type, target)
+}
+
+// redactURL masks the password of a URL with user:password@ in it, such as
+// a proxy URL; other values are returned as they are
+func redactURL(value string) string {
+	if !strings.Contains(value, "@") || !strings.Contains(value, "://") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	return u.Redacted()
+}
+
+// CacheConfig controls the local copy of the library used offline
+type CacheConfig struct {
+	Dir string `yaml:"dir" env:"CACHE_DIR" flag:"cache-dir" usage:"directory for the offline library cache (empty disables it)"`
+}
+
+type LogConfig struct {
+	Debug bool   `yaml:"debug" env:"DEBUG" flag:"debug" usage:"enable debug logging"`
+	Level string `yaml:"level" env:"LOG_LEVEL" flag:"log-level" usage:"log level: debug, info, warn or error"`
+	File  string `yaml:"file" env:"LOG_FILE" flag:"log-file" usage:"log file written while the TUI runs"`
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		GRPC: GRPCConfig{
+			Addr: "localhost:50051",
+			Retry: RetryConfig{
+				MaxAttempts:    4,
+				InitialBackoff: 200 * time.Millisecond,
+				MaxBackoff:     2 * time.Second,
+				Codes:          []string{"UNAVAILABLE"},
+			},
+		},
+		Notifier: NotifierConfig{
+			Addr:    "ws://localhost:8080",
+			Path:    "/ws/{user_id}",
+			Headers: map[string]string{},
+
+			ReconnectMin: time.Second,
+			ReconnectMax: time.Minute,
+			PingInterval: 30 * time.Second,
+			PongTimeout:  10 * time.Second,
+
+			CatchUp:   true,
+			CursorDir: defaultCursorDir(),
+		},
+		Timeouts: TimeoutConfig{
+			Connect:      20 * time.Second,
+			Login:        10 * time.Second,
+			Register:     10 * time.Second,
+			ListVideos:   15 * time.Second,
+			RecentVideos: 10 * time.Second,
+			GetVideo:     10 * time.Second,
+			Upload:       30 * time.Minute,
+		},
+		Transfer: TransferConfig{
+			MaxUploadMB: 500,
+			ChunkSizeKB: 64,
+			OutboxDir:   defaultOutboxDir(),
+		},
+		Session: SessionConfig{
+			IdleLock: 15 * time.Minute,
+		},
+		Notifications: NotificationConfig{
+			ToastTimeout: 5 * time.Second,
+			MaxToasts:    4,
+			RefreshDelay: 2 * time.Second,
+			DeliveryLog:  defaultDeliveryLog(),
+			Desktop:      string(internal.DesktopOff),
+			DesktopTypes: []string{string(internal.KindVideoReady), string(internal.KindProcessingFailed)},
+
+			HistoryDir:       defaultHistoryDir(),
+			HistoryRetention: 90 * 24 * time.Hour,
+			HistoryMax:       10000,
+		},
+		Keys: KeyConfig{
+			Upload:        "u",
+			Videos:        "v",
+			Notifications: "n",
+			Recent:        "s",
+			Refresh:       "r",
+			WebSocket:     "w",
+			MainMenu:      "m",
+			Logout:        "l",
+			Quit:          "q",
+			Lock:          "ctrl+l",
+			Reconnect:     "g",
+			Outbox:        "o",
+			Dismiss:       "ctrl+x",
+		},
+		Theme: ThemeConfig{
+			Background: "black",
+			Text:       "white",
+			Border:     "white",
+			Title:      "white",
+			Header:     "yellow",
+		},
+		Log: LogConfig{
+			Level: "info",
+			File:  defaultLogFile(),
+		},
+		Cache: CacheConfig{
+			Dir: defaultCacheDir(),
+		},
+		sources: map[string]Source{},
+	}
+}
+
+// Path returns the config file that was read, if any
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Source reports where the value for key (e.g. "grpc.addr") came from
+func (c *Config) Source(key string) Source {
+	if src, ok := c.sources[key]; ok {
+		return src
+	}
+	return Source{Kind: FromDefault}
+}
+
+// GRPCTLSOptions converts the gRPC TLS settings
+func (c *Config) GRPCTLSOptions() internal.TLSOptions {
+	return c.GRPC.TLS.options()
+}
+
+// ProxyOptions converts the proxy settings
+func (c *Config) ProxyOptions() internal.ProxyOptions {
+	return internal.ProxyOptions{
+		URL:     c.Proxy.URL,
+		NoProxy: c.Proxy.NoProxy,
+	}
+}
+
+// GRPCRetryPolicy converts the retry settings for read-only RPCs
+func (c *Config) GRPCRetryPolicy() internal.RetryPolicy {
+	r := c.GRPC.Retry
+	return internal.RetryPolicy{
+		MaxAttempts:    r.MaxAttempts,
+		InitialBackoff: r.InitialBackoff,
+		MaxBackoff:     r.MaxBackoff,
+		Codes:          r.Codes,
+	}
+}
+
+// NotificationRules compiles the notification rules and quiet hours
+func (c *Config) NotificationRules() ([]internal.Rule, internal.QuietHours, error) {
+	var rules []internal.Rule
+	var errs []error
+	for i, r := range c.Notifications.Rules {
+		rule, err := r.Rule()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d]: %v", i, err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	quiet, err := internal.ParseQuietHours(c.Notifications.QuietHours)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("quiet_hours: %v", err))
+	}
+	return rules, quiet, errors.Join(errs...)
+}
+
+// SinkOptions converts the sink settings
+func (c *Config) SinkOptions() []internal.SinkOptions {
+	out := make([]internal.SinkOptions, 0, len(c.Sinks))
+	for _, s := range c.Sinks {
+		opts := internal.SinkOptions{
+			Name:    s.Name,
+			Type:    s.Type,
+			URL:     s.URL,
+			Headers: s.Headers,
+			Command: s.Command,
+			Retries: s.Retries,
+			Timeout: s.Timeout,
+			Proxy:   c.ProxyOptions(),
+		}
+		for _, kind := range s.Types {
+			opts.Types = append(opts.Types, internal.EventKind(kind))
+		}
+		out = append(out, opts)
+	}
+	return out
+}
+
+// NotifierOptions converts the notifier settings
+func (c *Config) NotifierOptions() internal.NotifierOptions {
+	tls := c.Notifier.TLS.options()
+	if c.Notifier.TLS == (TLSConfig{}) {
+		tls = c.GRPC.TLS.options()
+	}
+	headers := make(map[string][]string, len(c.Notifier.Headers))
+	for name, value := range c.Notifier.Headers {
+		headers[name] = []string{value}
+	}
+	return internal.NotifierOptions{
+		Addr:             c.Notifier.Addr,
+		PathTemplate:     c.Notifier.Path,
+		TLS:              tls,
+		Headers:          headers,
+		Subprotocols:     c.Notifier.Subprotocols,
+		HandshakeTimeout: c.Timeouts.Connect,
+		Reconnect: internal.Backoff{
+			Min: c.Notifier.ReconnectMin,
+			Max: c.Notifier.ReconnectMax,
+		},
+		Proxy:        c.ProxyOptions(),
+		PingInterval: c.Notifier.PingInterval,
+		PongTimeout:  c.Notifier.PongTimeout,
+	}
+}
+
+func (t TLSConfig) options() internal.TLSOptions {
+	return internal.TLSOptions{
+		Insecure:   t.Insecure,
+		CAFile:     t.CA,
+		ServerName: t.ServerName,
+		CertFile:   t.Cert,
+		KeyFile:    t.Key,
+		SPKIPin:    t.Pin,
+	}
+}
+
+// Validate checks the configuration for values that cannot work
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.GRPC.Addr == "" {
+		add("grpc.addr", "must not be empty")
+	}
+	if err := c.ProxyOptions().Validate(); err != nil {
+		add("proxy.url", "%v", err)
+	}
+	if err := c.GRPCRetryPolicy().Validate(); err != nil {
+		add("grpc.retry", "%v", err)
+	}
+	for i, r := range c.Notifications.Rules {
+		if _, err := r.Rule(); err != nil {
+			add(fmt.Sprintf("notifications.rules[%d]", i), "%v", err)
+		}
+	}
+	if _, err := internal.ParseQuietHours(c.Notifications.QuietHours); err != nil {
+		add("notifications.quiet_hours", "%v", err)
+	}
+	if !slices.Contains(internal.DesktopModes, internal.DesktopMode(c.Notifications.Desktop)) {
+		add("notifications.desktop", "%q must be off, auto, osc9, osc777 or bell", c.Notifications.Desktop)
+	}
+	names := map[string]bool{}
+	for i, opts := range c.SinkOptions() {
+		key := fmt.Sprintf("sinks[%d]", i)
+		if err := opts.Validate(); err != nil {
+			add(key, "%v", err)
+		}
+		if names[opts.Name] {
+			add(key, "name %q is used by another sink", opts.Name)
+		}
+		names[opts.Name] = true
+	}
+	if !c.GRPC.TLS.Insecure {
+		if _, err := c.GRPCTLSOptions().Config(); err != nil {
+			add("grpc.tls", "%v", err)
+		}
+	}
+
+	notifier := c.NotifierOptions()
+	if u, err := notifier.URL("user"); err != nil {
+		add("notifier.addr", "%v", err)
+	} else if u.Scheme == "wss" {
+		tls := notifier.TLS
+		tls.Insecure = false
+		if _, err := tls.Config(); err != nil {
+			add("notifier.tls", "%v", err)
+		}
+	}
+
+	if c.Notifier.ReconnectMin <= 0 {
+		add("notifier.reconnect_min", "must be positive")
+	}
+	if c.Notifier.ReconnectMax < c.Notifier.ReconnectMin {
+		add("notifier.reconnect_max", "must not be below notifier.reconnect_min")
+	}
+	if c.Notifier.PingInterval < 0 {
+		add("notifier.ping_interval", "must not be negative")
+	}
+	if c.Notifier.PingInterval > 0 && c.Notifier.PongTimeout <= 0 {
+		add("notifier.pong_timeout", "must be positive when heartbeats are enabled")
+	}
+	if c.Timeouts.Connect <= 0 {
+		add("timeouts.connect", "must be positive")
+	}
+	for key, d := range map[string]time.Duration{
+		"timeouts.login":         c.Timeouts.Login,
+		"timeouts.register":      c.Timeouts.Register,
+		"timeouts.list_videos":   c.Timeouts.ListVideos,
+		"timeouts.recent_videos": c.Timeouts.RecentVideos,
+		"timeouts.get_video":     c.Timeouts.GetVideo,
+		"timeouts.upload":        c.Timeouts.Upload,
+	} {
+		if d < 0 {
+			add(key, "must not be negative")
+		}
+	}
+	if c.Transfer.MaxUploadMB <= 0 {
+		add("transfer.max_upload_mb", "must be positive")
+	}
+	if c.Transfer.ChunkSizeKB <= 0 || c.Transfer.ChunkSizeKB > 4096 {
+		add("transfer.chunk_size_kb", "must be between 1 and 4096")
+	}
+	if c.Session.IdleLock < 0 {
+		add("session.idle_lock", "must not be negative")
+	}
+	if c.Notifications.ToastTimeout < 0 {
+		add("notifications.toast_timeout", "must not be negative")
+	}
+	if c.Notifications.MaxToasts < 1 {
+		add("notifications.max_toasts", "must be at least 1")
+	}
+	if c.Notifications.RefreshDelay < 0 {
+		add("notifications.refresh_delay", "must not be negative")
+	}
+	if c.Notifications.HistoryRetention < 0 {
+		add("notifications.history_retention", "must not be negative")
+	}
+	if c.Notifications.HistoryMax < 0 {
+		add("notifications.history_max", "must not be negative")
+	}
+
+	// Compare parsed keys: runes are case sensitive, ctrl+<letter> is not
+	type parsedKey struct {
+		key tcell.Key
+		r   rune
+	}
+	seen := map[parsedKey]string{}
+	for _, binding := range c.keyBindings() {
+		key, r, err := ParseKey(binding.key)
+		if err != nil {
+			add("keys."+binding.name, "%v", err)
+			continue
+		}
+		if binding.global && key == tcell.KeyRune {
+			add("keys."+binding.name, "%q would fire while typing in a form; use ctrl+<letter>", binding.key)
+		}
+		if other, ok := seen[parsedKey{key, r}]; ok {
+			add("keys."+binding.name, "%q is already bound to %s", binding.key, other)
+		}
+		seen[parsedKey{key, r}] = binding.name
+	}
+
+	for key, color := range map[string]string{
+		"theme.background": c.Theme.Background,
+		"theme.text":       c.Theme.Text,
+		"theme.border":     c.Theme.Border,
+		"theme.title":      c.Theme.Title,
+		"theme.header":     c.Theme.Header,
+	} {
+		if !validColor(color) {
+			add(key, "unknown color %q", color)
+		}
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		add("log.level", "must be debug, info, warn or error")
+	}
+
+	return errors.Join(errs...)
+}
+
+type keyBinding struct {
+	name   string
+	key    string
+	global bool // caught in every view, text fields included
+}
+
+func (c *Config) keyBindings() []keyBinding {
+	k := c.Keys
+	return []keyBinding{
+		{"upload", k.Upload, false},
+		{"videos", k.Videos, false},
+		{"notifications", k.Notifications, false},
+		{"recent", k.Recent, false},
+		{"refresh", k.Refresh, false},
+		{"websocket", k.WebSocket, false},
+		{"main_menu", k.MainMenu, false},
+		{"logout", k.Logout, false},
+		{"quit", k.Quit, false},
+		{"lock", k.Lock, true},
+		{"reconnect", k.Reconnect, false},
+		{"outbox", k.Outbox, false},
+		{"dismiss", k.Dismiss, true},
+	}
+}
+
+// ParseKey parses a key binding: a single character or ctrl+<letter>.
+// Terminals send ctrl+h, ctrl+i and ctrl+m as Backspace, Tab and Enter, so
+// those cannot be bound.
+func ParseKey(binding string) (tcell.Key, rune, error) {
+	lower := strings.ToLower(binding)
+	if rest, ok := strings.CutPrefix(lower, "ctrl+"); ok {
+		if len(rest) != 1 || rest[0] < 'a' || rest[0] > 'z' {
+			return 0, 0, fmt.Errorf("invalid key %q, expected ctrl+<letter>", binding)
+		}
+		switch key := tcell.KeyCtrlA + tcell.Key(rest[0]-'a'); key {
+		case tcell.KeyBackspace:
+			return 0, 0, fmt.Errorf("invalid key %q, it is the same key as Backspace", binding)
+		case tcell.KeyTab:
+			return 0, 0, fmt.Errorf("invalid key %q, it is the same key as Tab", binding)
+		case tcell.KeyEnter:
+			return 0, 0, fmt.Errorf("invalid key %q, it is the same key as Enter", binding)
+		default:
+			return key, 0, nil
+		}
+	}
+	runes := []rune(binding)
+	if len(runes) != 1 {
+		return 0, 0, fmt.Errorf("invalid key %q, expected a single character or ctrl+<letter>", binding)
+	}
+	return tcell.KeyRune, runes[0], nil
+}
+
+// MatchKey reports whether the event matches a key binding
+func MatchKey(binding string, event *tcell.EventKey) bool {
+	key, r, err := ParseKey(binding)
+	if err != nil {
+		return false
+	}
+	if key == tcell.KeyRune {
+		return event.Key() == tcell.KeyRune && event.Rune() == r
+	}
+	return event.Key() == key
+}
+
+func validColor(name string) bool {
+	if strings.HasPrefix(name, "#") {
+		return tcell.GetColor(name) != tcell.ColorDefault
+	}
+	_, ok := tcell.ColorNames[strings.ToLower(name)]
+	return ok || name == "default"
+}
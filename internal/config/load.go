@@ -0,0 +1,530 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceKind identifies a configuration layer
+type SourceKind string
+
+const (
+	FromDefault SourceKind = "default"
+	FromFile    SourceKind = "file"
+	FromEnv     SourceKind = "env"
+	FromFlag    SourceKind = "flag"
+)
+
+// Source records where a value came from
+type Source struct {
+	Kind SourceKind
+	Name string // file path, variable or flag name
+}
+
+func (s Source) String() string {
+	switch s.Kind {
+	case FromFile:
+		return "file " + s.Name
+	case FromEnv:
+		return "env " + s.Name
+	case FromFlag:
+		return "flag --" + s.Name
+	}
+	return string(s.Kind)
+}
+
+// Setting is one resolved configuration value
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// DefaultPath returns the config file location in the XDG config directory
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "codek7.yaml"
+	}
+	return filepath.Join(dir, "codek7", "config.yaml")
+}
+
+// stateDir returns the XDG state directory for the app
+func stateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "codek7")
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "codek7")
+}
+
+func defaultLogFile() string {
+	return filepath.Join(stateDir(), "tui.log")
+}
+
+func defaultOutboxDir() string {
+	return filepath.Join(stateDir(), "outbox")
+}
+
+func defaultHistoryDir() string {
+	return filepath.Join(stateDir(), "notifications")
+}
+
+func defaultCursorDir() string {
+	return filepath.Join(stateDir(), "cursors")
+}
+
+func defaultDeliveryLog() string {
+	return filepath.Join(stateDir(), "deliveries.log")
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "codek7-cache")
+	}
+	return filepath.Join(dir, "codek7")
+}
+
+// Load builds the configuration from defaults, the config file, the
+// environment and args, and returns the arguments left after the flags.
+// The file is taken from --config, then CODEK7_CONFIG, then DefaultPath.
+// Commands add flags of their own through extra.
+func Load(name string, args []string, extra ...func(*flag.FlagSet)) (*Config, []string, error) {
+	cfg := Default()
+
+	path, explicit := configPathFromArgs(args)
+	if path == "" {
+		path, explicit = os.LookupEnv("CODEK7_CONFIG")
+	}
+	if path == "" {
+		path = DefaultPath()
+	}
+	if err := cfg.loadFile(path); err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			// No config file is fine; defaults apply
+		} else {
+			return nil, nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, nil, err
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.String("config", path, "config file (YAML)")
+	cfg.bindFlags(fs)
+	for _, add := range extra {
+		add(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// configPathFromArgs finds --config before the flag set is built
+func configPathFromArgs(args []string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if value, ok := strings.CutPrefix(name, "config="); ok {
+			return value, true
+		}
+		if name == "config" && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// field is a leaf configuration value with its lookup names
+type field struct {
+	key   string
+	env   string
+	flag  string
+	usage string
+	value reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (c *Config) fields() []field {
+	var out []field
+	walkFields(reflect.ValueOf(c).Elem(), "", "", "", &out)
+	return out
+}
+
+func walkFields(v reflect.Value, keyPrefix, envPrefix, flagPrefix string, out *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := keyPrefix + strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		env, flg := sf.Tag.Get("env"), sf.Tag.Get("flag")
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			walkFields(fv, key+".", envPrefix+env, flagPrefix+flg, out)
+			continue
+		}
+
+		f := field{key: key, usage: sf.Tag.Get("usage"), value: fv}
+		if env != "" {
+			f.env = envPrefix + env
+		}
+		if flg != "" {
+			f.flag = flagPrefix + flg
+		}
+		*out = append(*out, f)
+	}
+}
+
+func (f field) set(raw string) error {
+	switch p := f.value.Addr().Interface().(type) {
+	case *string:
+		*p = raw
+	case *bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s: expected true or false", f.key)
+		}
+		*p = b
+	case *int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%s: expected an integer", f.key)
+		}
+		*p = n
+	case *time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%s: expected a duration like 30s or 5m", f.key)
+		}
+		*p = d
+	case *[]string:
+		*p = nil
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*p = append(*p, item)
+			}
+		}
+	case *map[string]string:
+		m := make(map[string]string)
+		for _, pair := range strings.Split(raw, ";") {
+			name, value, ok := strings.Cut(pair, "=")
+			if name = strings.TrimSpace(name); !ok || name == "" {
+				continue
+			}
+			m[name] = strings.TrimSpace(value)
+		}
+		*p = m
+	default:
+		return fmt.Errorf("%s: unsupported type %s", f.key, f.value.Type())
+	}
+	return nil
+}
+
+func (f field) String() string {
+	switch v := f.value.Interface().(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case map[string]string:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		pairs := make([]string, 0, len(v))
+		for _, name := range names {
+			pairs = append(pairs, name+"="+v[name])
+		}
+		return strings.Join(pairs, ";")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	c.path = path
+	for _, f := range c.fields() {
+		if lookupKey(raw, f.key) {
+			c.sources[f.key] = Source{Kind: FromFile, Name: path}
+		}
+	}
+	return nil
+}
+
+func lookupKey(raw map[string]any, key string) bool {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		value, ok := raw[part]
+		if !ok {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if raw, ok = value.(map[string]any); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+func (c *Config) loadEnv() error {
+	var errs []error
+	for _, f := range c.fields() {
+		if f.env == "" {
+			continue
+		}
+		raw, ok := os.LookupEnv(f.env)
+		if !ok || raw == "" {
+			continue
+		}
+		if err := f.set(raw); err != nil {
+			errs = append(errs, fmt.Errorf("env %s: %w", f.env, err))
+			continue
+		}
+		c.sources[f.key] = Source{Kind: FromEnv, Name: f.env}
+	}
+	return errors.Join(errs...)
+}
+
+// flagValue adapts a config field to flag.Value
+type flagValue struct {
+	cfg *Config
+	f   field
+}
+
+func (v flagValue) String() string {
+	if !v.f.value.IsValid() {
+		return ""
+	}
+	return v.f.String()
+}
+
+func (v flagValue) Set(raw string) error {
+	if err := v.f.set(raw); err != nil {
+		return err
+	}
+	v.cfg.sources[v.f.key] = Source{Kind: FromFlag, Name: v.f.flag}
+	return nil
+}
+
+func (v flagValue) IsBoolFlag() bool {
+	return v.f.value.Kind() == reflect.Bool
+}
+
+func (c *Config) bindFlags(fs *flag.FlagSet) {
+	for _, f := range c.fields() {
+		if f.flag != "" {
+			fs.Var(flagValue{cfg: c, f: f}, f.flag, f.usage)
+		}
+	}
+}
+
+// Settings lists every resolved value with its source. Header values are
+// masked since they often carry credentials.
+func (c *Config) Settings() []Setting {
+	var out []Setting
+	for _, f := range c.fields() {
+		value := f.String()
+		if strings.HasSuffix(f.key, ".headers") && value != "" {
+			m := f.value.Interface().(map[string]string)
+			masked := make([]string, 0, len(m))
+			for name := range m {
+				masked = append(masked, name+"=***")
+			}
+			sort.Strings(masked)
+			value = strings.Join(masked, ";")
+		}
+		value = redactURL(value)
+		out = append(out, Setting{Key: f.key, Value: value, Source: c.Source(f.key)})
+	}
+	return out
+}
+
+// WriteDefault writes the built-in configuration to path as a commented
+// YAML file, creating parent directories as needed
+func WriteDefault(path string) error {
+	node, err := encodeNode(reflect.ValueOf(Default()).Elem())
+	if err != nil {
+		return err
+	}
+	node.HeadComment = "CodeK7 TUI configuration. Environment variables and flags override these values."
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+// SaveNotificationRules writes the notification rules and quiet hours into
+// the config file, keeping everything else in it. Without a config file
+// one is created at DefaultPath.
+func (c *Config) SaveNotificationRules() error {
+	path := c.path
+	if path == "" {
+		path = DefaultPath()
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("read config: %w", err)
+	default:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parse config %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config %s is not a YAML mapping", path)
+	}
+
+	rules := &yaml.Node{}
+	if err := rules.Encode(c.Notifications.Rules); err != nil {
+		return err
+	}
+	section := mappingEntry(root, "notifications", &yaml.Node{Kind: yaml.MappingNode})
+	if section.Kind != yaml.MappingNode {
+		*section = yaml.Node{Kind: yaml.MappingNode} // was empty
+	}
+	*mappingEntry(section, "rules", rules) = *rules
+	*mappingEntry(section, "quiet_hours", &yaml.Node{}) = yaml.Node{
+		Kind: yaml.ScalarNode, Tag: "!!str", Value: c.Notifications.QuietHours,
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// Write then rename so a crash never leaves a truncated config
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*")
+	if err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	c.path = path
+	for _, key := range []string{"notifications.rules", "notifications.quiet_hours"} {
+		if c.sources[key].Kind != FromEnv {
+			c.sources[key] = Source{Kind: FromFile, Name: path}
+		}
+	}
+	return nil
+}
+
+// mappingEntry returns the value under key in a YAML mapping, adding def
+// when the key is missing
+func mappingEntry(mapping *yaml.Node, key string, def *yaml.Node) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, def)
+	return def
+}
+
+// encodeNode renders a config struct in field order, with durations as
+// strings and usage tags as comments
+func encodeNode(v reflect.Value) (*yaml.Node, error) {
+	if v.Kind() == reflect.Struct {
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			child, err := encodeNode(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: strings.Split(sf.Tag.Get("yaml"), ",")[0]}
+			if usage := sf.Tag.Get("usage"); usage != "" {
+				key.HeadComment = usage
+			}
+			node.Content = append(node.Content, key, child)
+		}
+		return node, nil
+	}
+
+	if v.Type() == durationType {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: v.Interface().(time.Duration).String()}, nil
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(v.Interface()); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
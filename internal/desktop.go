@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DesktopMode selects how the terminal is asked to raise a desktop
+// notification
+type DesktopMode string
+
+const (
+	DesktopOff    DesktopMode = "off"
+	DesktopAuto   DesktopMode = "auto"   // pick from the environment
+	DesktopOSC9   DesktopMode = "osc9"   // iTerm2, WezTerm, kitty, Ghostty
+	DesktopOSC777 DesktopMode = "osc777" // VTE terminals, urxvt, foot
+	DesktopBell   DesktopMode = "bell"   // bell only
+)
+
+// DesktopModes lists the accepted modes
+var DesktopModes = []DesktopMode{DesktopOff, DesktopAuto, DesktopOSC9, DesktopOSC777, DesktopBell}
+
+// desktopMaxText caps the text put in an escape sequence; terminals drop
+// or truncate long ones anyway
+const desktopMaxText = 200
+
+// DetectDesktopMode guesses the sequence the terminal understands from its
+// environment, falling back to the bell. Inside tmux the variables of the
+// terminal tmux was started from are usually still set.
+func DetectDesktopMode(getenv func(string) string) DesktopMode {
+	term := getenv("TERM")
+	switch {
+	case getenv("LC_TERMINAL") == "iTerm2",
+		getenv("TERM_PROGRAM") == "iTerm.app",
+		getenv("TERM_PROGRAM") == "WezTerm", getenv("WEZTERM_PANE") != "",
+		getenv("TERM_PROGRAM") == "ghostty", getenv("GHOSTTY_RESOURCES_DIR") != "",
+		getenv("KITTY_WINDOW_ID") != "", strings.Contains(term, "kitty"):
+		return DesktopOSC9
+	case getenv("VTE_VERSION") != "",
+		strings.HasPrefix(term, "rxvt"), strings.HasPrefix(term, "foot"):
+		return DesktopOSC777
+	}
+	return DesktopBell
+}
+
+// DesktopSequence renders a desktop notification as an escape sequence for
+// the mode; empty for modes without one. With tmux set, the sequence is
+// wrapped so tmux passes it through to the outer terminal, which needs
+// "set -g allow-passthrough on".
+func DesktopSequence(mode DesktopMode, tmux bool, title, body string) string {
+	title, body = desktopText(title), desktopText(body)
+
+	var seq string
+	switch mode {
+	case DesktopOSC9:
+		if title != "" {
+			body = title + ": " + body
+		}
+		seq = "\x1b]9;" + body + "\x07"
+	case DesktopOSC777:
+		// The title ends at the first semicolon
+		seq = fmt.Sprintf("\x1b]777;notify;%s;%s\x07", strings.ReplaceAll(title, ";", ","), body)
+	default:
+		return ""
+	}
+
+	if tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// desktopText strips control characters, which would end the sequence
+// early or smuggle in escapes of their own
+func desktopText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n', r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimSpace(s)
+	if runes := []rune(s); len(runes) > desktopMaxText {
+		s = string(runes[:desktopMaxText-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,265 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	dispatchQueue      = 64               // events waiting per sink
+	deliveryLogSize    = 200              // deliveries kept in memory
+	defaultSinkTimeout = 10 * time.Second // per attempt, when the sink sets none
+	maxSinkBackoff     = 30 * time.Second
+)
+
+// Delivery is the outcome of forwarding one event to one sink
+type Delivery struct {
+	Sink     string    `json:"sink"`
+	EventID  string    `json:"event_id,omitempty"`
+	Kind     EventKind `json:"type"`
+	VideoID  string    `json:"video_id,omitempty"`
+	At       time.Time `json:"at"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Failed reports whether the event never reached the sink
+func (d Delivery) Failed() bool {
+	return d.Error != ""
+}
+
+// Dispatcher forwards events to sinks in the background. Each sink has its
+// own queue and worker, so a slow sink does not hold up the others; when a
+// queue is full the event is dropped, and the worker logs how many were
+// dropped as one failure once it is free. Outcomes are
+// kept in memory and appended as JSON lines to the delivery log.
+type Dispatcher struct {
+	workers []*sinkWorker
+	logPath string
+
+	mu         sync.Mutex
+	recent     []Delivery
+	onDelivery func(Delivery)
+
+	closing sync.RWMutex
+	closed  bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type sinkWorker struct {
+	sink    Sink
+	queue   chan Event
+	dropped atomic.Int64 // events dropped on a full queue, not yet logged
+}
+
+// NewDispatcher starts a worker per sink; logPath may be empty
+func NewDispatcher(sinks []Sink, logPath string) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{logPath: logPath, cancel: cancel}
+	for _, sink := range sinks {
+		w := &sinkWorker{sink: sink, queue: make(chan Event, dispatchQueue)}
+		d.workers = append(d.workers, w)
+		d.wg.Add(1)
+		go d.run(ctx, w)
+	}
+	return d
+}
+
+// Len returns the number of sinks
+func (d *Dispatcher) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.workers)
+}
+
+// SetDeliveryHandler registers a callback for each delivery outcome; it
+// runs on a worker goroutine
+func (d *Dispatcher) SetDeliveryHandler(fn func(Delivery)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onDelivery = fn
+}
+
+// Dispatch queues the event for every sink that accepts its kind. It never
+// blocks.
+func (d *Dispatcher) Dispatch(e Event) {
+	if d == nil {
+		return
+	}
+	d.closing.RLock()
+	defer d.closing.RUnlock()
+	if d.closed {
+		return
+	}
+	for _, w := range d.workers {
+		opts := w.sink.Options()
+		if !opts.Accepts(e.Kind) {
+			continue
+		}
+		select {
+		case w.queue <- e:
+		default:
+			// Logged by the worker: the caller may be the UI goroutine
+			w.dropped.Add(1)
+		}
+	}
+}
+
+// Deliveries returns the recent delivery outcomes, oldest first
+func (d *Dispatcher) Deliveries() []Delivery {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Delivery(nil), d.recent...)
+}
+
+// Close stops the workers, abandoning queued events after the timeout
+func (d *Dispatcher) Close(timeout time.Duration) {
+	if d == nil {
+		return
+	}
+	d.closing.Lock()
+	if d.closed {
+		d.closing.Unlock()
+		return
+	}
+	d.closed = true
+	for _, w := range d.workers {
+		close(w.queue)
+	}
+	d.closing.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+	d.cancel()
+}
+
+func (d *Dispatcher) run(ctx context.Context, w *sinkWorker) {
+	defer d.wg.Done()
+	for e := range w.queue {
+		attempts, err := d.deliver(ctx, w.sink, e)
+		d.record(deliveryFor(w.sink.Options().Name, e, attempts, err))
+		d.recordDropped(w)
+	}
+	d.recordDropped(w)
+}
+
+// recordDropped logs the events dropped since the last call as one failure
+func (d *Dispatcher) recordDropped(w *sinkWorker) {
+	n := w.dropped.Swap(0)
+	if n == 0 {
+		return
+	}
+	msg := "queue full, event dropped"
+	if n > 1 {
+		msg = fmt.Sprintf("queue full, %d events dropped", n)
+	}
+	d.record(Delivery{Sink: w.sink.Options().Name, At: time.Now(), Error: msg})
+}
+
+// deliver tries the sink until it succeeds, fails permanently or runs out
+// of retries, backing off between attempts
+func (d *Dispatcher) deliver(ctx context.Context, sink Sink, e Event) (int, error) {
+	opts := sink.Options()
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultSinkTimeout
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := sink.Deliver(attemptCtx, e)
+		cancel()
+
+		var permanent errPermanent
+		if err == nil || errors.As(err, &permanent) || attempt > opts.Retries {
+			return attempt, err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return attempt, err
+		}
+		backoff = min(backoff*2, maxSinkBackoff)
+	}
+}
+
+func deliveryFor(sink string, e Event, attempts int, err error) Delivery {
+	d := Delivery{
+		Sink:     sink,
+		EventID:  e.ID,
+		Kind:     e.Kind,
+		VideoID:  e.VideoID,
+		At:       time.Now(),
+		Attempts: attempts,
+	}
+	if err != nil {
+		d.Error = err.Error()
+	}
+	return d
+}
+
+func (d *Dispatcher) record(delivery Delivery) {
+	d.mu.Lock()
+	d.recent = append(d.recent, delivery)
+	if len(d.recent) > deliveryLogSize {
+		d.recent = d.recent[len(d.recent)-deliveryLogSize:]
+	}
+	fn := d.onDelivery
+	err := d.appendLog(delivery)
+	d.mu.Unlock()
+
+	if err != nil {
+		slog.Warn("Writing delivery log failed", "err", err)
+	}
+	if delivery.Failed() {
+		slog.Warn("Delivery failed", "kind", delivery.Kind, "sink", delivery.Sink,
+			"attempts", delivery.Attempts, "err", delivery.Error)
+	}
+	if fn != nil {
+		fn(delivery)
+	}
+}
+
+// appendLog writes the delivery to the log file; called with d.mu held
+func (d *Dispatcher) appendLog(delivery Delivery) error {
+	if d.logPath == "" {
+		return nil
+	}
+	line, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(d.logPath), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(d.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", d.logPath, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,237 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// EventSchemaVersion is the newest notification schema this client knows.
+// Frames without a version are treated as version 1.
+const EventSchemaVersion = 1
+
+// EventKind says what happened; kinds this client does not know are kept
+// as they are
+type EventKind string
+
+const (
+	KindUploadReceived     EventKind = "upload_received"
+	KindProcessingQueued   EventKind = "processing_queued"
+	KindProcessingStarted  EventKind = "processing_started"
+	KindProcessingProgress EventKind = "processing_progress"
+	KindVideoReady         EventKind = "video_ready"
+	KindProcessingFailed   EventKind = "processing_failed"
+	KindVideoRemoved       EventKind = "video_removed"
+	KindSystem             EventKind = "system"
+	KindNotification       EventKind = "notification" // a frame that names no type
+)
+
+// Known reports whether the kind is one this client understands
+func (k EventKind) Known() bool {
+	switch k {
+	case KindUploadReceived, KindProcessingQueued, KindProcessingStarted, KindProcessingProgress,
+		KindVideoReady, KindProcessingFailed, KindVideoRemoved, KindSystem, KindNotification:
+		return true
+	}
+	return false
+}
+
+// Event is one notification from the notifier
+type Event struct {
+	Version   int
+	ID        string
+	Kind      EventKind
+	VideoID   string
+	Timestamp time.Time // server time; zero if the server sent none
+	Message   string
+	Payload   json.RawMessage // kind-specific details
+
+	// Fields this client does not know, kept so nothing the server sent is
+	// lost when the event is stored
+	Extra map[string]json.RawMessage
+}
+
+// ProgressPayload details a processing_progress event
+type ProgressPayload struct {
+	Percent float64 `json:"percent"`
+	Stage   string  `json:"stage,omitempty"`
+}
+
+// FailurePayload details a processing_failed event
+type FailurePayload struct {
+	Reason string `json:"reason"`
+}
+
+// DecodeEvent parses a notifier frame. Frames of a newer schema version
+// are rejected, as their fields may not mean what this client takes them
+// to; they are shown like any other frame that could not be decoded.
+func DecodeEvent(data []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, err
+	}
+	if e.Version > EventSchemaVersion {
+		return e, fmt.Errorf("notification schema version %d is newer than this client supports (%d)",
+			e.Version, EventSchemaVersion)
+	}
+	return e, nil
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return fmt.Errorf("notification is not a JSON object")
+	}
+
+	take := func(name string, dst any) error {
+		raw, ok := fields[name]
+		if !ok {
+			return nil
+		}
+		delete(fields, name)
+		if bytes.Equal(raw, []byte("null")) {
+			return nil
+		}
+		if err := json.Unmarshal(raw, dst); err != nil {
+			return fmt.Errorf("notification field %q: %v", name, err)
+		}
+		return nil
+	}
+
+	*e = Event{Version: 1}
+	var kind string
+	var ts json.RawMessage
+	for _, f := range []struct {
+		name string
+		dst  any
+	}{
+		{"version", &e.Version},
+		{"id", &e.ID},
+		{"type", &kind},
+		{"video_id", &e.VideoID},
+		{"timestamp", &ts},
+		{"message", &e.Message},
+		{"payload", &e.Payload},
+	} {
+		if err := take(f.name, f.dst); err != nil {
+			return err
+		}
+	}
+	// Notifiers that predate typed events send plain notifications
+	if kind == "" {
+		kind = string(KindNotification)
+	}
+	e.Kind = EventKind(kind)
+
+	if len(ts) > 0 {
+		t, err := parseTimestamp(ts)
+		if err != nil {
+			return fmt.Errorf("notification field \"timestamp\": %v", err)
+		}
+		e.Timestamp = t
+	}
+	if len(fields) > 0 {
+		e.Extra = fields
+	}
+	return nil
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]any, len(e.Extra)+7)
+	for name, raw := range e.Extra {
+		fields[name] = raw
+	}
+	fields["version"] = e.Version
+	fields["type"] = e.Kind
+	if e.ID != "" {
+		fields["id"] = e.ID
+	}
+	if e.VideoID != "" {
+		fields["video_id"] = e.VideoID
+	}
+	if !e.Timestamp.IsZero() {
+		fields["timestamp"] = e.Timestamp
+	}
+	if e.Message != "" {
+		fields["message"] = e.Message
+	}
+	if len(e.Payload) > 0 {
+		fields["payload"] = e.Payload
+	}
+	return json.Marshal(fields)
+}
+
+// parseTimestamp accepts RFC 3339 strings and Unix times in seconds or
+// milliseconds
+func parseTimestamp(raw json.RawMessage) (time.Time, error) {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	n, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want an RFC 3339 string or Unix time, got %s", raw)
+	}
+	if n > 1e12 {
+		return time.UnixMilli(int64(n)), nil
+	}
+	sec := int64(n)
+	return time.Unix(sec, int64((n-float64(sec))*1e9)), nil
+}
+
+// Progress decodes the payload of a processing_progress event
+func (e Event) Progress() (ProgressPayload, bool) {
+	var p ProgressPayload
+	if e.Kind != KindProcessingProgress || json.Unmarshal(e.Payload, &p) != nil {
+		return p, false
+	}
+	return p, true
+}
+
+// Failure decodes the payload of a processing_failed event
+func (e Event) Failure() (FailurePayload, bool) {
+	var p FailurePayload
+	if e.Kind != KindProcessingFailed || json.Unmarshal(e.Payload, &p) != nil {
+		return p, false
+	}
+	return p, true
+}
+
+// Summary describes the event in one line, preferring the server's message
+func (e Event) Summary() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	video := e.VideoID
+	if video == "" {
+		video = "your video"
+	}
+	switch e.Kind {
+	case KindUploadReceived:
+		return fmt.Sprintf("Upload of %s received", video)
+	case KindProcessingQueued:
+		return fmt.Sprintf("Processing of %s queued", video)
+	case KindProcessingStarted:
+		return fmt.Sprintf("Processing of %s started", video)
+	case KindProcessingProgress:
+		if p, ok := e.Progress(); ok {
+			if p.Stage != "" {
+				return fmt.Sprintf("Processing %s: %s %.0f%%", video, p.Stage, p.Percent)
+			}
+			return fmt.Sprintf("Processing %s: %.0f%%", video, p.Percent)
+		}
+		return fmt.Sprintf("Processing %s", video)
+	case KindVideoReady:
+		return fmt.Sprintf("Video %s is ready", video)
+	case KindProcessingFailed:
+		if p, ok := e.Failure(); ok && p.Reason != "" {
+			return fmt.Sprintf("Processing of %s failed: %s", video, p.Reason)
+		}
+		return fmt.Sprintf("Processing of %s failed", video)
+	case KindVideoRemoved:
+		return fmt.Sprintf("Video %s was removed", video)
+	}
+	return string(e.Kind)
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// NotifierOptions describes how to reach the notification WebSocket
+type NotifierOptions struct {
+	Addr         string // base URL, ws:// or wss://
+	PathTemplate string // appended to Addr; {user_id} is replaced
+	TLS          TLSOptions
+	Headers      http.Header
+	Subprotocols []string
+	Proxy        ProxyOptions
+
+	HandshakeTimeout time.Duration
+	Reconnect        Backoff
+	PingInterval     time.Duration // 0 disables heartbeats
+	PongTimeout      time.Duration
+
+	// Since asks the notifier to replay events after this cursor value;
+	// sent as the since query parameter when set
+	Since string
+}
+
+// URL builds the notifier URL for a user. For a unix: address the URL
+// names localhost; the request itself goes over the socket.
+func (o NotifierOptions) URL(userID string) (*url.URL, error) {
+	base := strings.TrimRight(o.Addr, "/")
+	if _, ok := UnixSocketPath(o.Addr); ok {
+		base = "ws://localhost"
+	}
+	path := strings.ReplaceAll(o.PathTemplate, "{user_id}", url.PathEscape(userID))
+	if path != "" && !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "?") {
+		path = "/" + path
+	}
+
+	u, err := url.Parse(base + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notifier URL: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("notifier URL %q must use ws:// or wss://", u.Redacted())
+	}
+	if o.Since != "" {
+		query := u.Query()
+		query.Set("since", o.Since)
+		u.RawQuery = query.Encode()
+	}
+	return u, nil
+}
+
+// DialNotifier opens the notification WebSocket for a user
+func DialNotifier(ctx context.Context, opts NotifierOptions, userID string) (*websocket.Conn, error) {
+	u, err := opts.URL(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := websocket.Dialer{
+		NetDialContext:   opts.Proxy.DialContext,
+		HandshakeTimeout: opts.HandshakeTimeout,
+		Subprotocols:     opts.Subprotocols,
+	}
+	target := u.Redacted()
+	if path, ok := UnixSocketPath(opts.Addr); ok {
+		dialer.NetDialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return DialUnix(ctx, path)
+		}
+		target = fmt.Sprintf("unix://%s (%s)", path, u.RequestURI())
+	}
+	if u.Scheme == "wss" {
+		tlsOpts := opts.TLS
+		tlsOpts.Insecure = false // the URL scheme decides
+		cfg, err := tlsOpts.Config()
+		if err != nil {
+			return nil, err
+		}
+		dialer.TLSClientConfig = cfg
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, u.String(), opts.Headers.Clone())
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+			return nil, fmt.Errorf("notifier %s rejected the upgrade: %s", target, resp.Status)
+		}
+		if u.Scheme == "wss" && isTLSError(err) {
+			return nil, ExplainTLSError(u.Host, err)
+		}
+		return nil, fmt.Errorf("connect to notifier %s: %w", target, err)
+	}
+
+	if len(opts.Subprotocols) > 0 && conn.Subprotocol() == "" {
+		conn.Close()
+		return nil, fmt.Errorf("notifier accepted none of the subprotocols %v", opts.Subprotocols)
+	}
+	return conn, nil
+}
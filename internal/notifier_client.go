@@ -0,0 +1,297 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ConnState is the lifecycle state of a notifier connection
+type ConnState int
+
+const (
+	StateStopped ConnState = iota
+	StateConnecting
+	StateConnected
+	StateBackoff
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateBackoff:
+		return "backoff"
+	}
+	return "stopped"
+}
+
+// ErrStale means the server stopped answering heartbeats
+var ErrStale = errors.New("connection stale")
+
+// ConnStatus is a snapshot of the connection lifecycle
+type ConnStatus struct {
+	State   ConnState
+	Since   time.Time // when State was entered
+	Attempt int       // failed attempts since the last successful connect
+	Err     error     // why the last connection ended, if it failed
+	RetryAt time.Time // next attempt while in StateBackoff
+
+	LastMessage time.Time // last data frame received, zero if none yet
+	LastPong    time.Time // last heartbeat answer
+}
+
+// NotifierHandlers receive connection events; they run on the supervisor
+// goroutine and must not block. A session stopped while a handler runs
+// calls none after it.
+type NotifierHandlers struct {
+	OnState   func(ConnStatus)
+	OnMessage func(data []byte)
+	// Since is asked before every connect for the catch-up cursor
+	Since func() string
+}
+
+// NotifierClient keeps a notifier WebSocket open, reconnecting with
+// backoff until it is stopped. Each Start begins a new session with its
+// own stop channel, so Start and Stop can be paired any number of times;
+// a stopped session winds down in the background and no longer touches
+// the client.
+type NotifierClient struct {
+	opts     NotifierOptions
+	handlers NotifierHandlers
+
+	mu          sync.Mutex
+	status      ConnStatus
+	lastMessage time.Time
+	lastPong    time.Time
+	conn        *websocket.Conn
+	stopCh      chan struct{} // the current session's, nil when stopped
+}
+
+func NewNotifierClient(opts NotifierOptions, handlers NotifierHandlers) *NotifierClient {
+	return &NotifierClient{
+		opts:     opts,
+		handlers: handlers,
+		status:   ConnStatus{State: StateStopped, Since: time.Now()},
+	}
+}
+
+// Start begins a session for userID; it returns false if one is running
+func (c *NotifierClient) Start(userID string) bool {
+	c.mu.Lock()
+	if c.stopCh != nil {
+		c.mu.Unlock()
+		return false
+	}
+	stopCh := make(chan struct{})
+	c.stopCh = stopCh
+	c.mu.Unlock()
+
+	go c.run(userID, stopCh)
+	return true
+}
+
+// Stop ends the current session. It does not wait for the session to wind
+// down, so it is safe to call while a handler is blocked on the caller.
+func (c *NotifierClient) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopCh == nil {
+		return
+	}
+	close(c.stopCh)
+	c.stopCh = nil
+	if c.conn != nil {
+		c.conn.Close() // unblocks the reader
+		c.conn = nil
+	}
+	c.status = ConnStatus{State: StateStopped, Since: time.Now()}
+}
+
+// current reports whether stopCh belongs to the running session
+func (c *NotifierClient) current(stopCh chan struct{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopCh == stopCh
+}
+
+// Status returns the current lifecycle snapshot
+func (c *NotifierClient) Status() ConnStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := c.status
+	status.LastMessage = c.lastMessage
+	status.LastPong = c.lastPong
+	return status
+}
+
+// setStatus records a state change of the session stopCh belongs to and
+// reports it, unless that session has been stopped
+func (c *NotifierClient) setStatus(status ConnStatus, stopCh chan struct{}) {
+	status.Since = time.Now()
+	c.mu.Lock()
+	if c.stopCh != stopCh {
+		c.mu.Unlock()
+		return
+	}
+	c.status = status
+	status.LastMessage = c.lastMessage
+	status.LastPong = c.lastPong
+	c.mu.Unlock()
+
+	if c.handlers.OnState != nil {
+		c.handlers.OnState(status)
+	}
+}
+
+// setConn publishes the session's connection so Stop can close it; it
+// returns false once the session has been stopped
+func (c *NotifierClient) setConn(conn *websocket.Conn, stopCh chan struct{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopCh != stopCh {
+		return false
+	}
+	c.conn = conn
+	return true
+}
+
+func (c *NotifierClient) run(userID string, stopCh chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	stopped := func() bool {
+		select {
+		case <-stopCh:
+			return true
+		default:
+			return false
+		}
+	}
+
+	attempt := 0
+	for {
+		c.setStatus(ConnStatus{State: StateConnecting, Attempt: attempt}, stopCh)
+
+		opts := c.opts
+		if c.handlers.Since != nil {
+			opts.Since = c.handlers.Since()
+		}
+		conn, err := DialNotifier(ctx, opts, userID)
+		if err == nil {
+			if !c.setConn(conn, stopCh) {
+				conn.Close()
+				break
+			}
+			attempt = 0
+			c.setStatus(ConnStatus{State: StateConnected}, stopCh)
+			err = c.read(conn, stopCh)
+			conn.Close()
+			c.setConn(nil, stopCh)
+		}
+
+		if stopped() {
+			break
+		}
+
+		attempt++
+		delay := c.opts.Reconnect.Delay(attempt)
+		c.setStatus(ConnStatus{
+			State:   StateBackoff,
+			Attempt: attempt,
+			Err:     err,
+			RetryAt: time.Now().Add(delay),
+		}, stopCh)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-stopCh:
+			timer.Stop()
+		case <-timer.C:
+		}
+		if stopped() {
+			break
+		}
+	}
+}
+
+// read delivers frames until the connection fails or is closed. With a
+// ping interval set, it pings the server and treats a missing pong as a
+// stale connection, which ends the read and triggers a reconnect.
+func (c *NotifierClient) read(conn *websocket.Conn, stopCh chan struct{}) error {
+	interval, wait := c.opts.PingInterval, c.opts.PongTimeout
+	if interval <= 0 {
+		return c.readFrames(conn, 0, stopCh)
+	}
+
+	window := interval + wait
+	c.touch(&c.lastPong)
+	conn.SetPongHandler(func(string) error {
+		c.touch(&c.lastPong)
+		return conn.SetReadDeadline(time.Now().Add(window))
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				deadline := time.Now().Add(wait)
+				if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	err := c.readFrames(conn, window, stopCh)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		c.mu.Lock()
+		last := c.lastPong
+		c.mu.Unlock()
+		return fmt.Errorf("%w: no pong for %s", ErrStale, time.Since(last).Round(time.Second))
+	}
+	return err
+}
+
+func (c *NotifierClient) readFrames(conn *websocket.Conn, window time.Duration, stopCh chan struct{}) error {
+	for {
+		if window > 0 {
+			conn.SetReadDeadline(time.Now().Add(window))
+		}
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if !c.current(stopCh) {
+			return nil
+		}
+		c.touch(&c.lastMessage)
+		if c.handlers.OnMessage != nil {
+			c.handlers.OnMessage(data)
+		}
+	}
+}
+
+func (c *NotifierClient) touch(t *time.Time) {
+	c.mu.Lock()
+	*t = time.Now()
+	c.mu.Unlock()
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrFileChanged means a queued file no longer matches what was queued
+var ErrFileChanged = errors.New("file changed since it was queued")
+
+// OutboxItem is an upload waiting for the server
+type OutboxItem struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Path        string    `json:"path"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+	SHA256      string    `json:"sha256"`
+	QueuedAt    time.Time `json:"queued_at"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	Conflict    bool      `json:"conflict,omitempty"` // held back until the user accepts or drops it
+}
+
+// Outbox is an ordered queue of uploads persisted to a JSON file. Every
+// change is written before the call returns, so queued uploads survive
+// restarts and crashes.
+type Outbox struct {
+	path string
+
+	mu    sync.Mutex
+	items []OutboxItem
+}
+
+// OpenOutbox loads the outbox stored at path; a missing file is empty
+func OpenOutbox(path string) (*Outbox, error) {
+	o := &Outbox{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	if err := json.Unmarshal(data, &o.items); err != nil {
+		return nil, fmt.Errorf("read outbox %s: %w", path, err)
+	}
+	return o, nil
+}
+
+// NewOutboxItem snapshots a file for queuing, including its checksum
+func NewOutboxItem(path, title, description, userID string) (OutboxItem, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return OutboxItem{}, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return OutboxItem{}, err
+	}
+	sum, err := FileChecksum(abs)
+	if err != nil {
+		return OutboxItem{}, err
+	}
+	now := time.Now()
+	return OutboxItem{
+		ID:          fmt.Sprintf("%d", now.UnixNano()),
+		UserID:      userID,
+		Path:        abs,
+		Title:       title,
+		Description: description,
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		SHA256:      sum,
+		QueuedAt:    now,
+	}, nil
+}
+
+// FileChecksum returns the hex SHA-256 of a file
+func FileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify checks that the file is still the one that was queued. Size and
+// modification time are compared first; the checksum settles the rest.
+func (it OutboxItem) Verify() error {
+	info, err := os.Stat(it.Path)
+	if err != nil {
+		return err
+	}
+	if info.Size() != it.Size {
+		return ErrFileChanged
+	}
+	if info.ModTime().Equal(it.ModTime) {
+		return nil
+	}
+	sum, err := FileChecksum(it.Path)
+	if err != nil {
+		return err
+	}
+	if sum != it.SHA256 {
+		return ErrFileChanged
+	}
+	return nil
+}
+
+// Items returns the queue in upload order
+func (o *Outbox) Items() []OutboxItem {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return slices.Clone(o.items)
+}
+
+// Len returns the number of queued uploads
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.items)
+}
+
+// Add appends an item to the end of the queue
+func (o *Outbox) Add(item OutboxItem) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.items = append(o.items, item)
+	if err := o.save(); err != nil {
+		o.items = o.items[:len(o.items)-1]
+		return err
+	}
+	return nil
+}
+
+// Update replaces the item with the same ID
+func (o *Outbox) Update(item OutboxItem) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	i := o.index(item.ID)
+	if i < 0 {
+		return nil // dropped meanwhile
+	}
+	o.items[i] = item
+	return o.save()
+}
+
+// Remove drops an item from the queue
+func (o *Outbox) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	i := o.index(id)
+	if i < 0 {
+		return nil
+	}
+	o.items = slices.Delete(o.items, i, i+1)
+	return o.save()
+}
+
+func (o *Outbox) index(id string) int {
+	return slices.IndexFunc(o.items, func(it OutboxItem) bool { return it.ID == id })
+}
+
+func (o *Outbox) save() error {
+	data, err := json.MarshalIndent(o.items, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(o.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create outbox directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".outbox-*")
+	if err != nil {
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	return os.Rename(tmp.Name(), o.path)
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Stage is a step of the processing pipeline a video goes through after
+// upload
+type Stage string
+
+const (
+	StageReceived   Stage = "received"
+	StageQueued     Stage = "queued"
+	StageProcessing Stage = "processing"
+	StageReady      Stage = "ready"
+	StageFailed     Stage = "failed"
+)
+
+// Done reports whether the pipeline ends at this stage
+func (s Stage) Done() bool {
+	return s == StageReady || s == StageFailed
+}
+
+// StageFor maps an event to the pipeline stage it moves a video into
+func StageFor(kind EventKind) (Stage, bool) {
+	switch kind {
+	case KindUploadReceived:
+		return StageReceived, true
+	case KindProcessingQueued:
+		return StageQueued, true
+	case KindProcessingStarted, KindProcessingProgress:
+		return StageProcessing, true
+	case KindVideoReady:
+		return StageReady, true
+	case KindProcessingFailed:
+		return StageFailed, true
+	}
+	return "", false
+}
+
+// Step is one stage of a video's timeline
+type Step struct {
+	Stage   Stage
+	Started time.Time
+	Ended   time.Time // zero while the video is in this stage
+
+	// Latest processing progress, or why processing failed
+	Percent float64
+	Detail  string
+}
+
+// Duration is how long the step took, or has taken so far
+func (s Step) Duration(now time.Time) time.Duration {
+	if s.Ended.IsZero() {
+		if s.Stage.Done() {
+			return 0
+		}
+		return now.Sub(s.Started)
+	}
+	return s.Ended.Sub(s.Started)
+}
+
+// Pipeline is the processing timeline of one video, built from the events
+// that carry its ID
+type Pipeline struct {
+	VideoID string
+	Steps   []Step
+}
+
+// Apply moves the pipeline along for an event that happened at the given
+// time. Events that are not about processing are ignored.
+func (p *Pipeline) Apply(e Event, at time.Time) {
+	stage, ok := StageFor(e.Kind)
+	if !ok {
+		return
+	}
+
+	// A new upload of the same video starts over
+	if stage == StageReceived {
+		p.Steps = nil
+	}
+
+	if n := len(p.Steps); n == 0 || p.Steps[n-1].Stage != stage {
+		if n > 0 && p.Steps[n-1].Ended.IsZero() {
+			p.Steps[n-1].Ended = at
+		}
+		p.Steps = append(p.Steps, Step{Stage: stage, Started: at})
+	}
+
+	step := &p.Steps[len(p.Steps)-1]
+	if stage.Done() {
+		step.Ended = at
+	}
+	switch e.Kind {
+	case KindProcessingProgress:
+		if progress, ok := e.Progress(); ok {
+			step.Percent = progress.Percent
+			step.Detail = progress.Stage
+		}
+	case KindProcessingFailed:
+		if failure, ok := e.Failure(); ok {
+			step.Detail = failure.Reason
+		}
+	case KindVideoReady:
+		step.Percent = 100
+	}
+}
+
+// Current returns the stage the video is in
+func (p *Pipeline) Current() (Step, bool) {
+	if p == nil || len(p.Steps) == 0 {
+		return Step{}, false
+	}
+	return p.Steps[len(p.Steps)-1], true
+}
+
+// Status describes the current stage in a few words
+func (p *Pipeline) Status() string {
+	step, ok := p.Current()
+	if !ok {
+		return ""
+	}
+	switch step.Stage {
+	case StageProcessing:
+		switch {
+		case step.Detail != "" && step.Percent > 0:
+			return fmt.Sprintf("processing %.0f%% (%s)", step.Percent, step.Detail)
+		case step.Percent > 0:
+			return fmt.Sprintf("processing %.0f%%", step.Percent)
+		}
+		return "processing"
+	case StageFailed:
+		if step.Detail != "" {
+			return "failed: " + step.Detail
+		}
+	}
+	return string(step.Stage)
+}
+
+// TimedEvent is an event with the time it happened
+type TimedEvent struct {
+	Event Event
+	At    time.Time
+}
+
+// BuildPipelines replays events, in time order, into a timeline per video
+func BuildPipelines(events []TimedEvent) map[string]*Pipeline {
+	sorted := make([]TimedEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].At.Before(sorted[j].At)
+	})
+
+	pipelines := map[string]*Pipeline{}
+	for _, te := range sorted {
+		if te.Event.VideoID == "" {
+			continue
+		}
+		p := pipelines[te.Event.VideoID]
+		if p == nil {
+			p = &Pipeline{VideoID: te.Event.VideoID}
+			pipelines[te.Event.VideoID] = p
+		}
+		p.Apply(te.Event, te.At)
+	}
+	for id, p := range pipelines {
+		if len(p.Steps) == 0 {
+			delete(pipelines, id)
+		}
+	}
+	return pipelines
+}
@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// ProxyOptions describes how to reach the backend through a proxy. An
+// empty URL falls back to HTTPS_PROXY, then ALL_PROXY; "direct" disables
+// proxying even when those are set. NoProxy uses the NO_PROXY syntax and
+// falls back to NO_PROXY. As with NO_PROXY, localhost and loopback
+// addresses are always dialed directly.
+type ProxyOptions struct {
+	URL     string // http://, https://, socks5:// or socks5h://, with optional user:password@
+	NoProxy string
+}
+
+// resolve returns the proxy URL and no-proxy rules, applying the
+// environment fallback. A nil URL means connect directly.
+func (o ProxyOptions) resolve() (*url.URL, string, error) {
+	raw := o.URL
+	if raw == "" {
+		raw = getenvAny("HTTPS_PROXY", "https_proxy", "ALL_PROXY", "all_proxy")
+	}
+	noProxy := o.NoProxy
+	if noProxy == "" {
+		noProxy = getenvAny("NO_PROXY", "no_proxy")
+	}
+	if raw == "" || raw == "direct" {
+		return nil, noProxy, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		// The parse error quotes the URL, password included
+		return nil, "", errors.New("invalid proxy URL")
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("invalid proxy URL %q", u.Redacted())
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, "", fmt.Errorf("proxy URL %q must use http, https, socks5 or socks5h", u.Redacted())
+	}
+	return u, noProxy, nil
+}
+
+// Validate checks the proxy settings
+func (o ProxyOptions) Validate() error {
+	_, _, err := o.resolve()
+	return err
+}
+
+// ProxyFor returns the proxy used for addr (host:port), or nil when addr
+// is dialed directly
+func (o ProxyOptions) ProxyFor(addr string) (*url.URL, error) {
+	if _, ok := UnixSocketPath(addr); ok {
+		return nil, nil
+	}
+	u, noProxy, err := o.resolve()
+	if err != nil || u == nil {
+		return nil, err
+	}
+	cfg := httpproxy.Config{HTTPSProxy: u.String(), NoProxy: noProxy}
+	return cfg.ProxyFunc()(&url.URL{Scheme: "https", Host: addr})
+}
+
+// Dial connects to addr over TCP, or to a unix: address directly; it fits
+// grpc.WithContextDialer
+func (o ProxyOptions) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	if path, ok := UnixSocketPath(addr); ok {
+		return DialUnix(ctx, path)
+	}
+	return o.DialContext(ctx, "tcp", addr)
+}
+
+// DialContext connects to addr, through the proxy when one applies; it
+// fits websocket.Dialer.NetDialContext
+func (o ProxyOptions) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var direct net.Dialer
+	p, err := o.ProxyFor(addr)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return direct.DialContext(ctx, network, addr)
+	}
+
+	switch p.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if p.User != nil {
+			password, _ := p.User.Password()
+			auth = &proxy.Auth{User: p.User.Username(), Password: password}
+		}
+		// The proxy resolves the host name for socks5h; socks5 resolves
+		// locally first, as curl does
+		if p.Scheme == "socks5" {
+			if addr, err = resolveLocal(ctx, addr); err != nil {
+				return nil, err
+			}
+		}
+		dialer, err := proxy.SOCKS5("tcp", canonicalProxyAddr(p), auth, &direct)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := dialer.(proxy.ContextDialer).DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, fmt.Errorf("connect to %s via SOCKS5 proxy %s: %w", addr, p.Host, err)
+		}
+		return conn, nil
+	}
+	return dialConnect(ctx, p, addr)
+}
+
+// dialConnect opens a tunnel to addr with an HTTP CONNECT request
+func dialConnect(ctx context.Context, p *url.URL, addr string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", canonicalProxyAddr(p))
+	if err != nil {
+		return nil, fmt.Errorf("connect to proxy %s: %w", p.Host, err)
+	}
+	if p.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: p.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, ExplainTLSError(p.Host, err)
+		}
+		conn = tlsConn
+	}
+
+	// Give up on the handshake with the context
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if p.User != nil {
+		password, _ := p.User.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(p.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err := req.Write(conn); err != nil {
+		stop()
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", p.Host, err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		stop()
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("proxy %s: %w", p.Host, err)
+	}
+	resp.Body.Close()
+	if !stop() {
+		return nil, ctx.Err() // the tunnel was closed by the context
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		if resp.StatusCode == http.StatusProxyAuthRequired {
+			return nil, fmt.Errorf("proxy %s requires authentication (%s); put user:password in the proxy URL", p.Host, resp.Status)
+		}
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", p.Host, addr, resp.Status)
+	}
+
+	// The server may already have sent bytes that the reader buffered
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func canonicalProxyAddr(p *url.URL) string {
+	if p.Port() != "" {
+		return p.Host
+	}
+	port := "80"
+	switch p.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(p.Hostname(), port)
+}
+
+// lookupHost resolves names for socks5 proxies; replaced in tests
+var lookupHost = net.DefaultResolver.LookupHost
+
+func resolveLocal(ctx context.Context, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(host) != nil {
+		return addr, nil
+	}
+	ips, err := lookupHost(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(ips[0], port), nil
+}
+
+func getenvAny(names ...string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	proto "github.com/codek7-services/codek7-tui/pkg/pb"
+	"google.golang.org/grpc/codes"
+)
+
+// Read-only RPCs are safe to repeat, so they are retried on transient errors
+var retryableMethods = []string{
+	proto.RepoService_GetUserVideos_FullMethodName,
+	proto.RepoService_GetVideoByID_FullMethodName,
+	proto.RepoService_GetLast3UserVideos_FullMethodName,
+}
+
+// RPCs with side effects must never be repeated automatically; a retry
+// after a lost response could create a duplicate user or remove twice
+var neverRetriedMethods = []string{
+	proto.RepoService_CreateUser_FullMethodName,
+	proto.RepoService_RemoveVideo_FullMethodName,
+	proto.RepoService_UploadVideo_FullMethodName,
+}
+
+// RetryPolicy describes how read-only RPCs are retried
+type RetryPolicy struct {
+	MaxAttempts    int // including the first call; 1 disables retries
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Codes          []string // status code names, e.g. UNAVAILABLE
+}
+
+// Validate checks the policy against the limits gRPC enforces
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 || p.MaxAttempts > 5 {
+		return fmt.Errorf("max attempts must be between 1 and 5, got %d", p.MaxAttempts)
+	}
+	if p.MaxAttempts == 1 {
+		return nil
+	}
+	if p.InitialBackoff <= 0 || p.MaxBackoff < p.InitialBackoff {
+		return fmt.Errorf("backoff must be positive and max backoff at least the initial one")
+	}
+	if len(p.Codes) == 0 {
+		return fmt.Errorf("at least one retryable status code is required")
+	}
+	for _, name := range p.Codes {
+		if _, err := parseCode(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServiceConfig renders the policy as a gRPC service config
+func (p RetryPolicy) ServiceConfig() string {
+	type name struct {
+		Service string `json:"service"`
+		Method  string `json:"method"`
+	}
+	type retryPolicy struct {
+		MaxAttempts          int      `json:"maxAttempts"`
+		InitialBackoff       string   `json:"initialBackoff"`
+		MaxBackoff           string   `json:"maxBackoff"`
+		BackoffMultiplier    float64  `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	type methodConfig struct {
+		Name        []name       `json:"name"`
+		RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+	}
+
+	names := func(methods []string) []name {
+		var out []name
+		for _, m := range methods {
+			service, method, _ := strings.Cut(strings.TrimPrefix(m, "/"), "/")
+			out = append(out, name{Service: service, Method: method})
+		}
+		return out
+	}
+
+	// Side-effecting RPCs get an entry without a policy so no broader
+	// default can ever apply to them
+	configs := []methodConfig{{Name: names(neverRetriedMethods)}}
+	if p.MaxAttempts > 1 {
+		codes := make([]string, len(p.Codes))
+		for i, c := range p.Codes {
+			codes[i] = strings.ToUpper(c)
+		}
+		configs = append(configs, methodConfig{
+			Name: names(retryableMethods),
+			RetryPolicy: &retryPolicy{
+				MaxAttempts:          p.MaxAttempts,
+				InitialBackoff:       seconds(p.InitialBackoff),
+				MaxBackoff:           seconds(p.MaxBackoff),
+				BackoffMultiplier:    2,
+				RetryableStatusCodes: codes,
+			},
+		})
+	}
+
+	data, _ := json.Marshal(map[string]any{"methodConfig": configs})
+	return string(data)
+}
+
+// seconds formats a duration the way service configs expect ("0.2s")
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
+
+func parseCode(name string) (codes.Code, error) {
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(`"` + strings.ToUpper(name) + `"`)); err != nil {
+		return 0, fmt.Errorf("unknown status code %q", name)
+	}
+	return c, nil
+}
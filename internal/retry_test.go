@@ -0,0 +1,311 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	proto "github.com/codek7-services/codek7-tui/pkg/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// flakyRepo fails every RPC with code until it has been called failures
+// times, and counts the calls per method
+type flakyRepo struct {
+	proto.UnimplementedRepoServiceServer
+	code     codes.Code
+	failures int
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (r *flakyRepo) call(method string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[method]++
+	if r.calls[method] <= r.failures {
+		return status.Error(r.code, "injected failure")
+	}
+	return nil
+}
+
+func (r *flakyRepo) count(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[method]
+}
+
+func (r *flakyRepo) CreateUser(context.Context, *proto.CreateUserRequest) (*proto.UserResponse, error) {
+	if err := r.call(proto.RepoService_CreateUser_FullMethodName); err != nil {
+		return nil, err
+	}
+	return &proto.UserResponse{Id: "u1"}, nil
+}
+
+func (r *flakyRepo) UploadVideo(stream grpc.ClientStreamingServer[proto.UploadVideoRequest, proto.VideoMetadataResponse]) error {
+	if err := r.call(proto.RepoService_UploadVideo_FullMethodName); err != nil {
+		return err
+	}
+	for {
+		if _, err := stream.Recv(); err != nil {
+			return stream.SendAndClose(&proto.VideoMetadataResponse{Id: "v1"})
+		}
+	}
+}
+
+func (r *flakyRepo) GetUserVideos(context.Context, *proto.GetUserVideosRequest) (*proto.VideoListResponse, error) {
+	if err := r.call(proto.RepoService_GetUserVideos_FullMethodName); err != nil {
+		return nil, err
+	}
+	return &proto.VideoListResponse{}, nil
+}
+
+func (r *flakyRepo) GetLast3UserVideos(context.Context, *proto.GetLast3UserVideosRequest) (*proto.Video3ListResponse, error) {
+	if err := r.call(proto.RepoService_GetLast3UserVideos_FullMethodName); err != nil {
+		return nil, err
+	}
+	return &proto.Video3ListResponse{}, nil
+}
+
+func (r *flakyRepo) GetVideoByID(context.Context, *proto.GetVideoRequest) (*proto.VideoMetadataResponse, error) {
+	if err := r.call(proto.RepoService_GetVideoByID_FullMethodName); err != nil {
+		return nil, err
+	}
+	return &proto.VideoMetadataResponse{Id: "v1"}, nil
+}
+
+func (r *flakyRepo) RemoveVideo(context.Context, *proto.GetVideoRequest) (*emptypb.Empty, error) {
+	if err := r.call(proto.RepoService_RemoveVideo_FullMethodName); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// dialFlakyRepo serves repo in memory and connects to it the way the TUI
+// does, with the policy as the only service config
+func dialFlakyRepo(t *testing.T, repo *flakyRepo, policy RetryPolicy) proto.RepoServiceClient {
+	t.Helper()
+	repo.calls = map[string]int{}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	proto.RegisterRepoServiceServer(srv, repo)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDisableServiceConfig(),
+		grpc.WithDefaultServiceConfig(policy.ServiceConfig()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return proto.NewRepoServiceClient(conn)
+}
+
+func testRetryPolicy(attempts int, codes ...string) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    attempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Codes:          codes,
+	}
+}
+
+// repoCalls invokes each RPC once, by full method name
+var repoCalls = map[string]func(context.Context, proto.RepoServiceClient) error{
+	proto.RepoService_GetUserVideos_FullMethodName: func(ctx context.Context, c proto.RepoServiceClient) error {
+		_, err := c.GetUserVideos(ctx, &proto.GetUserVideosRequest{UserId: "u1"})
+		return err
+	},
+	proto.RepoService_GetVideoByID_FullMethodName: func(ctx context.Context, c proto.RepoServiceClient) error {
+		_, err := c.GetVideoByID(ctx, &proto.GetVideoRequest{VideoId: "v1"})
+		return err
+	},
+	proto.RepoService_GetLast3UserVideos_FullMethodName: func(ctx context.Context, c proto.RepoServiceClient) error {
+		_, err := c.GetLast3UserVideos(ctx, &proto.GetLast3UserVideosRequest{UserId: "u1"})
+		return err
+	},
+	proto.RepoService_CreateUser_FullMethodName: func(ctx context.Context, c proto.RepoServiceClient) error {
+		_, err := c.CreateUser(ctx, &proto.CreateUserRequest{Username: "bob"})
+		return err
+	},
+	proto.RepoService_RemoveVideo_FullMethodName: func(ctx context.Context, c proto.RepoServiceClient) error {
+		_, err := c.RemoveVideo(ctx, &proto.GetVideoRequest{VideoId: "v1"})
+		return err
+	},
+	proto.RepoService_UploadVideo_FullMethodName: func(ctx context.Context, c proto.RepoServiceClient) error {
+		stream, err := c.UploadVideo(ctx)
+		if err != nil {
+			return err
+		}
+		stream.Send(&proto.UploadVideoRequest{Data: &proto.UploadVideoRequest_Metadata{
+			Metadata: &proto.VideoMetadata{UserId: "u1", Title: "clip"},
+		}})
+		_, err = stream.CloseAndRecv()
+		return err
+	},
+}
+
+func TestRetryPolicyRetriesReadOnlyRPCs(t *testing.T) {
+	for _, code := range []codes.Code{codes.Unavailable, codes.ResourceExhausted} {
+		for _, method := range []string{
+			proto.RepoService_GetUserVideos_FullMethodName,
+			proto.RepoService_GetVideoByID_FullMethodName,
+			proto.RepoService_GetLast3UserVideos_FullMethodName,
+		} {
+			t.Run(code.String()+method, func(t *testing.T) {
+				repo := &flakyRepo{code: code, failures: 2}
+				client := dialFlakyRepo(t, repo, testRetryPolicy(3, "UNAVAILABLE", "resource_exhausted"))
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := repoCalls[method](ctx, client); err != nil {
+					t.Fatalf("call failed despite retries: %v", err)
+				}
+				if got := repo.count(method); got != 3 {
+					t.Fatalf("server saw %d calls, want 3", got)
+				}
+			})
+		}
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   codes.Code
+		policy RetryPolicy
+		want   int // calls the server sees
+	}{
+		{"after max attempts", codes.Unavailable, testRetryPolicy(3, "UNAVAILABLE"), 3},
+		{"on codes not listed", codes.Internal, testRetryPolicy(3, "UNAVAILABLE"), 1},
+		{"when retries are off", codes.Unavailable, testRetryPolicy(1), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &flakyRepo{code: tt.code, failures: 10}
+			client := dialFlakyRepo(t, repo, tt.policy)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			method := proto.RepoService_GetUserVideos_FullMethodName
+			err := repoCalls[method](ctx, client)
+			if status.Code(err) != tt.code {
+				t.Fatalf("got %v, want code %s", err, tt.code)
+			}
+			if got := repo.count(method); got != tt.want {
+				t.Fatalf("server saw %d calls, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyNeverRetriesSideEffects(t *testing.T) {
+	for _, code := range []codes.Code{codes.Unavailable, codes.ResourceExhausted} {
+		for _, method := range []string{
+			proto.RepoService_CreateUser_FullMethodName,
+			proto.RepoService_RemoveVideo_FullMethodName,
+			proto.RepoService_UploadVideo_FullMethodName,
+		} {
+			t.Run(code.String()+method, func(t *testing.T) {
+				repo := &flakyRepo{code: code, failures: 1}
+				client := dialFlakyRepo(t, repo, testRetryPolicy(5, "UNAVAILABLE", "RESOURCE_EXHAUSTED"))
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				err := repoCalls[method](ctx, client)
+				if status.Code(err) != code {
+					t.Fatalf("got %v, want code %s", err, code)
+				}
+				if got := repo.count(method); got != 1 {
+					t.Fatalf("server saw %d calls, want 1", got)
+				}
+			})
+		}
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		ok     bool
+	}{
+		{"default", testRetryPolicy(3, "UNAVAILABLE"), true},
+		{"retries off", RetryPolicy{MaxAttempts: 1}, true},
+		{"lower case code", testRetryPolicy(2, "unavailable"), true},
+		{"no attempts", RetryPolicy{}, false},
+		{"more attempts than gRPC allows", testRetryPolicy(6, "UNAVAILABLE"), false},
+		{"no codes", testRetryPolicy(3), false},
+		{"unknown code", testRetryPolicy(3, "SOMETIMES"), false},
+		{"max backoff below initial", RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second,
+			MaxBackoff: time.Millisecond, Codes: []string{"UNAVAILABLE"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err == nil) != tt.ok {
+				t.Fatalf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyServiceConfig(t *testing.T) {
+	var cfg struct {
+		MethodConfig []struct {
+			Name []struct {
+				Service string `json:"service"`
+				Method  string `json:"method"`
+			} `json:"name"`
+			RetryPolicy *struct {
+				MaxAttempts          int      `json:"maxAttempts"`
+				InitialBackoff       string   `json:"initialBackoff"`
+				MaxBackoff           string   `json:"maxBackoff"`
+				RetryableStatusCodes []string `json:"retryableStatusCodes"`
+			} `json:"retryPolicy"`
+		} `json:"methodConfig"`
+	}
+	policy := RetryPolicy{MaxAttempts: 4, InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff: 2 * time.Second, Codes: []string{"unavailable"}}
+	if err := json.Unmarshal([]byte(policy.ServiceConfig()), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.MethodConfig) != 2 {
+		t.Fatalf("got %d method configs, want 2", len(cfg.MethodConfig))
+	}
+	never, retried := cfg.MethodConfig[0], cfg.MethodConfig[1]
+	if never.RetryPolicy != nil || len(never.Name) != len(neverRetriedMethods) {
+		t.Fatalf("side-effecting methods: %+v", never)
+	}
+	r := retried.RetryPolicy
+	if r == nil || r.MaxAttempts != 4 || r.InitialBackoff != "0.2s" || r.MaxBackoff != "2s" ||
+		len(r.RetryableStatusCodes) != 1 || r.RetryableStatusCodes[0] != "UNAVAILABLE" {
+		t.Fatalf("retry policy: %+v", r)
+	}
+	if len(retried.Name) != len(retryableMethods) || retried.Name[0].Service != "repo.RepoService" {
+		t.Fatalf("read-only methods: %+v", retried.Name)
+	}
+
+	// Without retries only the side-effecting entry is left
+	if err := json.Unmarshal([]byte(RetryPolicy{MaxAttempts: 1}.ServiceConfig()), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.MethodConfig) != 1 {
+		t.Fatalf("got %d method configs with retries off, want 1", len(cfg.MethodConfig))
+	}
+}
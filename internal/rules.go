@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RuleAction says what a matching rule does to a notification
+type RuleAction string
+
+const (
+	ActionMute     RuleAction = "mute"     // stored as read, never shown
+	ActionPriority RuleAction = "priority" // sticky toast and bell, even in quiet hours
+	ActionDismiss  RuleAction = "dismiss"  // shown briefly, stored as read
+)
+
+// RuleActions lists the actions in the order they are offered
+var RuleActions = []RuleAction{ActionMute, ActionPriority, ActionDismiss}
+
+// Rule matches notifications by type, message and video. Every condition
+// that is set must match.
+type Rule struct {
+	Name    string
+	Types   []EventKind // any of these; empty matches every type
+	Message string      // regular expression matched against the summary
+	VideoID string
+	Action  RuleAction
+
+	message *regexp.Regexp
+}
+
+// Compile checks the rule and prepares its message expression
+func (r *Rule) Compile() error {
+	if !slices.Contains(RuleActions, r.Action) {
+		return fmt.Errorf("action %q must be mute, priority or dismiss", r.Action)
+	}
+	r.message = nil
+	if r.Message != "" {
+		re, err := regexp.Compile(r.Message)
+		if err != nil {
+			return fmt.Errorf("message: %v", err)
+		}
+		r.message = re
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to the event
+func (r *Rule) Matches(e Event) bool {
+	if len(r.Types) > 0 && !slices.Contains(r.Types, e.Kind) {
+		return false
+	}
+	if r.VideoID != "" && r.VideoID != e.VideoID {
+		return false
+	}
+	if r.message != nil && !r.message.MatchString(e.Summary()) {
+		return false
+	}
+	return true
+}
+
+// QuietHours is a daily period, possibly past midnight, in local time
+type QuietHours struct {
+	Start, End time.Duration // since midnight
+	set        bool
+}
+
+// ParseQuietHours parses "22:00-07:00"; an empty string means none
+func ParseQuietHours(s string) (QuietHours, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return QuietHours{}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return QuietHours{}, errors.New("want HH:MM-HH:MM")
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	if start == end {
+		return QuietHours{}, errors.New("start and end must differ")
+	}
+	return QuietHours{Start: start, End: end, set: true}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", strings.TrimSpace(s))
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls in the quiet period
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.set {
+		return false
+	}
+	h, m, s := t.Clock()
+	now := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	if q.Start < q.End {
+		return now >= q.Start && now < q.End
+	}
+	return now >= q.Start || now < q.End
+}
+
+func (q QuietHours) String() string {
+	if !q.set {
+		return ""
+	}
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(q.Start) + "-" + clock(q.End)
+}
+
+// Decision is what the rules say to do with a notification
+type Decision struct {
+	Action RuleAction // empty when no rule matched
+	Rule   string     // name of the matching rule
+	Quiet  bool       // inside quiet hours
+}
+
+// Interrupts reports whether the notification may be surfaced right away
+func (d Decision) Interrupts() bool {
+	switch {
+	case d.Action == ActionMute:
+		return false
+	case d.Action == ActionPriority:
+		return true
+	}
+	return !d.Quiet
+}
+
+// Decide applies the first matching rule and the quiet hours
+func Decide(rules []Rule, quiet QuietHours, e Event, at time.Time) Decision {
+	d := Decision{Quiet: quiet.Contains(at)}
+	for i := range rules {
+		if rules[i].Matches(e) {
+			d.Action, d.Rule = rules[i].Action, rules[i].Name
+			break
+		}
+	}
+	return d
+}
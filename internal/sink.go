@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sink types
+const (
+	SinkWebhook = "webhook"
+	SinkShell   = "shell"
+)
+
+// SinkOptions describes where notifications are forwarded
+type SinkOptions struct {
+	Name    string
+	Type    string // SinkWebhook or SinkShell
+	URL     string // webhook target
+	Headers map[string]string
+	Command string      // shell command, run with sh -c
+	Types   []EventKind // kinds to forward; empty forwards every kind
+	Retries int         // further attempts after a failed delivery
+	Timeout time.Duration
+	Proxy   ProxyOptions
+}
+
+// Validate checks the sink settings
+func (o SinkOptions) Validate() error {
+	if o.Name == "" {
+		return errors.New("name is required")
+	}
+	switch o.Type {
+	case SinkWebhook:
+		u, err := url.Parse(o.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url %q must be an http:// or https:// URL", o.URL)
+		}
+	case SinkShell:
+		if strings.TrimSpace(o.Command) == "" {
+			return errors.New("command is required")
+		}
+	default:
+		return fmt.Errorf("type %q must be %s or %s", o.Type, SinkWebhook, SinkShell)
+	}
+	if o.Retries < 0 || o.Retries > 10 {
+		return errors.New("retries must be between 0 and 10")
+	}
+	if o.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	return nil
+}
+
+// Accepts reports whether events of this kind go to the sink
+func (o SinkOptions) Accepts(kind EventKind) bool {
+	return len(o.Types) == 0 || slices.Contains(o.Types, kind)
+}
+
+// Sink delivers one event somewhere
+type Sink interface {
+	Options() SinkOptions
+	Deliver(ctx context.Context, e Event) error
+}
+
+// NewSink builds the sink the options describe
+func NewSink(opts SinkOptions) (Sink, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("sink %q: %w", opts.Name, err)
+	}
+	if opts.Type == SinkShell {
+		return &shellSink{opts: opts}, nil
+	}
+	return &webhookSink{
+		opts: opts,
+		client: &http.Client{
+			Transport: &http.Transport{DialContext: opts.Proxy.DialContext},
+			// Each attempt gets the sink timeout through its context
+		},
+	}, nil
+}
+
+// errPermanent marks failures that retrying will not fix
+type errPermanent struct{ err error }
+
+func (e errPermanent) Error() string { return e.err.Error() }
+func (e errPermanent) Unwrap() error { return e.err }
+
+type webhookSink struct {
+	opts   SinkOptions
+	client *http.Client
+}
+
+func (s *webhookSink) Options() SinkOptions { return s.opts }
+
+// Deliver POSTs the event as JSON
+func (s *webhookSink) Deliver(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return errPermanent{err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return errPermanent{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "codek7-tui")
+	for name, value := range s.opts.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	err = fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return err
+	}
+	return errPermanent{err}
+}
+
+type shellSink struct {
+	opts SinkOptions
+}
+
+func (s *shellSink) Options() SinkOptions { return s.opts }
+
+// Deliver runs the command with the event in CODEK7_* variables and as JSON
+// on stdin
+func (s *shellSink) Deliver(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return errPermanent{err}
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.opts.Command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), EventEnv(e)...)
+	cmd.Env = append(cmd.Env, "CODEK7_EVENT_JSON="+string(body))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			if len(msg) > 256 {
+				msg = msg[len(msg)-256:]
+			}
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// EventEnv renders the event fields as CODEK7_* environment variables
+func EventEnv(e Event) []string {
+	env := []string{
+		"CODEK7_EVENT_ID=" + e.ID,
+		"CODEK7_EVENT_TYPE=" + string(e.Kind),
+		"CODEK7_EVENT_VERSION=" + fmt.Sprint(e.Version),
+		"CODEK7_VIDEO_ID=" + e.VideoID,
+		"CODEK7_EVENT_MESSAGE=" + e.Summary(),
+	}
+	if !e.Timestamp.IsZero() {
+		env = append(env, "CODEK7_EVENT_TIMESTAMP="+e.Timestamp.Format(time.RFC3339))
+	}
+	if p, ok := e.Progress(); ok {
+		env = append(env, fmt.Sprintf("CODEK7_PROGRESS=%.0f", p.Percent), "CODEK7_STAGE="+p.Stage)
+	}
+	if p, ok := e.Failure(); ok {
+		env = append(env, "CODEK7_FAILURE_REASON="+p.Reason)
+	}
+	return env
+}
@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	standInQueue     = 64 // events waiting per connection before it is dropped
+	standInMaxBody   = 1 << 20
+	standInWriteWait = 10 * time.Second
+)
+
+// StandInNotifier is a small local notifier for development and for
+// servers that do not support catch-up yet. Events POSTed to
+// /events/{user_id} are kept per user and pushed to that user's
+// WebSocket connections. A client connecting with ?since=<event ID or
+// RFC 3339 time> first gets the events after it, marked "replayed".
+type StandInNotifier struct {
+	keep int // events kept per user
+	mux  *http.ServeMux
+
+	mu     sync.Mutex
+	events map[string][]Event
+	subs   map[string]map[chan Event]bool
+	nextID int
+}
+
+// NewStandInNotifier serves WebSockets at path, a pattern like
+// /ws/{user_id}
+func NewStandInNotifier(path string, keep int) *StandInNotifier {
+	s := &StandInNotifier{
+		keep:   keep,
+		mux:    http.NewServeMux(),
+		events: map[string][]Event{},
+		subs:   map[string]map[chan Event]bool{},
+	}
+	s.mux.HandleFunc("GET "+path, s.serveWS)
+	s.mux.HandleFunc("POST /events/{user_id}", s.servePublish)
+	return s
+}
+
+func (s *StandInNotifier) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Publish stores the event for the user and pushes it to the user's
+// connections. Missing IDs and timestamps are filled in.
+func (s *StandInNotifier) Publish(userID string, e Event) Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	if e.ID == "" {
+		e.ID = fmt.Sprintf("evt-%d-%d", time.Now().Unix(), s.nextID)
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	if e.Version == 0 {
+		e.Version = EventSchemaVersion
+	}
+
+	events := append(s.events[userID], e)
+	if len(events) > s.keep {
+		events = events[len(events)-s.keep:]
+	}
+	s.events[userID] = events
+
+	for ch := range s.subs[userID] {
+		select {
+		case ch <- e:
+		default:
+			// Too slow; closing makes the writer hang up
+			delete(s.subs[userID], ch)
+			close(ch)
+		}
+	}
+	return e
+}
+
+// replay returns the user's events after since. An unknown event ID
+// replays everything kept; the client drops what it already has.
+func (s *StandInNotifier) replay(userID, since string) []Event {
+	events := s.events[userID]
+	if since == "" {
+		return nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, since); err == nil {
+		for i, e := range events {
+			if e.Timestamp.After(t) {
+				return events[i:]
+			}
+		}
+		return nil
+	}
+	for i, e := range events {
+		if e.ID == since {
+			return events[i+1:]
+		}
+	}
+	return events
+}
+
+func (s *StandInNotifier) servePublish(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	body, err := io.ReadAll(io.LimitReader(r.Body, standInMaxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// One event, or one per line
+	var published []Event
+	for _, line := range strings.Split(string(body), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		e, err := DecodeEvent([]byte(line))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		published = append(published, e)
+	}
+	for i, e := range published {
+		published[i] = s.Publish(userID, e)
+		log.Printf("Published %s %s for %s", published[i].Kind, published[i].ID, userID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(published)
+}
+
+func (s *StandInNotifier) serveWS(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	if protocols := websocket.Subprotocols(r); len(protocols) > 0 {
+		upgrader.Subprotocols = protocols[:1] // accept whatever is offered
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // the upgrader has answered
+	}
+	defer conn.Close()
+
+	// Queue the replay and subscribe in one step, so nothing published in
+	// between is lost
+	since := r.URL.Query().Get("since")
+	ch := make(chan Event, standInQueue)
+	s.mu.Lock()
+	backlog := s.replay(userID, since)
+	if s.subs[userID] == nil {
+		s.subs[userID] = map[chan Event]bool{}
+	}
+	s.subs[userID][ch] = true
+	s.mu.Unlock()
+	log.Printf("Client %s connected (since %q, replaying %d)", userID, since, len(backlog))
+
+	defer func() {
+		s.mu.Lock()
+		if s.subs[userID][ch] {
+			delete(s.subs[userID], ch)
+			close(ch)
+		}
+		s.mu.Unlock()
+		log.Printf("Client %s disconnected", userID)
+	}()
+
+	// Reading answers pings and notices when the client goes away
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, e := range backlog {
+		e.Extra = maps.Clone(e.Extra)
+		if e.Extra == nil {
+			e.Extra = map[string]json.RawMessage{}
+		}
+		e.Extra["replayed"] = json.RawMessage("true")
+		if !s.send(conn, e) {
+			return
+		}
+	}
+	for {
+		select {
+		case <-gone:
+			return
+		case e, ok := <-ch:
+			if !ok || !s.send(conn, e) {
+				return
+			}
+		}
+	}
+}
+
+func (s *StandInNotifier) send(conn *websocket.Conn, e Event) bool {
+	data, err := json.Marshal(e)
+	if err != nil {
+		slog.Warn("Encoding event failed", "id", e.ID, "err", err)
+		return true
+	}
+	conn.SetWriteDeadline(time.Now().Add(standInWriteWait))
+	return conn.WriteMessage(websocket.TextMessage, data) == nil
+}
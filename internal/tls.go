@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TLSOptions describes how to secure a connection to the backend
+type TLSOptions struct {
+	Insecure   bool   // plaintext, no TLS at all
+	CAFile     string // PEM bundle of trusted roots; system pool if empty
+	ServerName string // overrides the name checked against the certificate
+	CertFile   string // client certificate for mutual TLS
+	KeyFile    string // client key for mutual TLS
+	SPKIPin    string // base64 SHA-256 of the server's SubjectPublicKeyInfo
+}
+
+// Config builds the tls.Config for these options
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: o.ServerName,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, errors.New("mutual TLS needs both a client certificate and a key")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if o.SPKIPin != "" {
+		pin, err := base64.StdEncoding.DecodeString(o.SPKIPin)
+		if err != nil || len(pin) != sha256.Size {
+			return nil, errors.New("SPKI pin must be a base64 SHA-256 digest")
+		}
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			sum := sha256.Sum256(cs.PeerCertificates[0].RawSubjectPublicKeyInfo)
+			if string(sum[:]) != string(pin) {
+				return fmt.Errorf("server key does not match pinned SPKI (got %s)",
+					base64.StdEncoding.EncodeToString(sum[:]))
+			}
+			return nil
+		}
+	}
+
+	return cfg, nil
+}
+
+// TransportCredentials returns gRPC credentials for these options
+func (o TLSOptions) TransportCredentials() (credentials.TransportCredentials, error) {
+	if o.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+	cfg, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	return &explainedCreds{TransportCredentials: credentials.NewTLS(cfg)}, nil
+}
+
+// explainedCreds annotates handshake failures with a hint about the fix
+type explainedCreds struct {
+	credentials.TransportCredentials
+}
+
+func (c *explainedCreds) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	tlsConn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, conn)
+	if err != nil {
+		return nil, nil, ExplainTLSError(authority, err)
+	}
+	return tlsConn, info, nil
+}
+
+func (c *explainedCreds) Clone() credentials.TransportCredentials {
+	return &explainedCreds{TransportCredentials: c.TransportCredentials.Clone()}
+}
+
+// ExplainTLSError turns a TLS handshake error into a message naming the likely cause
+func ExplainTLSError(server string, err error) error {
+	var (
+		unknownAuth x509.UnknownAuthorityError
+		hostErr     x509.HostnameError
+		invalid     x509.CertificateInvalidError
+		recordErr   tls.RecordHeaderError
+	)
+
+	hint := ""
+	switch {
+	case errors.As(err, &unknownAuth):
+		hint = "certificate is signed by an unknown authority; set a CA bundle"
+	case errors.As(err, &hostErr):
+		hint = "certificate does not match the server name; set a server-name override"
+	case errors.As(err, &invalid):
+		hint = "certificate is invalid or expired"
+	case errors.As(err, &recordErr):
+		hint = "server does not speak TLS; use --insecure for a plaintext server"
+	case strings.Contains(err.Error(), "pinned SPKI"):
+		hint = "server key changed or the pin is wrong"
+	case strings.Contains(err.Error(), "certificate required"),
+		strings.Contains(err.Error(), "bad certificate"):
+		hint = "server requires a valid client certificate (mutual TLS)"
+	}
+
+	if hint == "" {
+		return fmt.Errorf("TLS handshake with %s failed: %w", server, err)
+	}
+	return fmt.Errorf("TLS handshake with %s failed: %s: %w", server, hint, err)
+}
+
+// isTLSError reports whether err came out of a TLS handshake
+func isTLSError(err error) bool {
+	var (
+		verifyErr *tls.CertificateVerificationError
+		recordErr tls.RecordHeaderError
+		alertErr  tls.AlertError
+	)
+	return errors.As(err, &verifyErr) || errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) || strings.Contains(err.Error(), "tls:") ||
+		strings.Contains(err.Error(), "pinned SPKI")
+}
@@ -0,0 +1,328 @@
+package internal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPKI is a throwaway CA with a server and a client certificate. The
+// server certificate is only valid for server.test, so connecting to the
+// loopback address needs the server-name override.
+type testPKI struct {
+	caFile     string
+	server     tls.Certificate
+	clientCert string
+	clientKey  string
+	serverPin  string // base64 SHA-256 of the server's SPKI
+	caPool     *x509.CertPool
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey := newTestKey(t)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key := newTestKey(t)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der, key
+	}
+
+	p := &testPKI{caPool: x509.NewCertPool()}
+	p.caPool.AddCert(ca)
+	p.caFile = writePEM(t, dir, "ca.pem", "CERTIFICATE", caDER)
+
+	serverDER, serverKey := issue(2, "server.test", x509.ExtKeyUsageServerAuth)
+	p.server = tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+	serverCert, err := x509.ParseCertificate(serverDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(serverCert.RawSubjectPublicKeyInfo)
+	p.serverPin = base64.StdEncoding.EncodeToString(sum[:])
+
+	clientDER, clientKey := issue(3, "client.test", x509.ExtKeyUsageClientAuth)
+	p.clientCert = writePEM(t, dir, "client.pem", "CERTIFICATE", clientDER)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.clientKey = writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+	return p
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, dir, name, kind string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// serveTLS accepts connections with the server certificate and answers
+// each with one byte once the handshake is done
+func (p *testPKI) serveTLS(t *testing.T, requireClientCert bool) string {
+	t.Helper()
+	cfg := &tls.Config{Certificates: []tls.Certificate{p.server}}
+	if requireClientCert {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = p.caPool
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if conn.(*tls.Conn).Handshake() == nil {
+					conn.Write([]byte{1})
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// dialTLS connects with the options and reads the server's byte, which is
+// where a rejected client certificate surfaces under TLS 1.3
+func dialTLS(t *testing.T, addr string, opts TLSOptions) error {
+	t.Helper()
+	cfg, err := opts.Config()
+	if err != nil {
+		t.Fatalf("Config: %v", err)
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", addr, cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	return err
+}
+
+func TestTLSOptionsConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := pki.serveTLS(t, false)
+	mtlsAddr := pki.serveTLS(t, true)
+
+	tests := []struct {
+		name    string
+		addr    string
+		opts    TLSOptions
+		wantErr string // substring of the error; empty for success
+	}{
+		{
+			name: "trusted CA and server name override",
+			addr: addr,
+			opts: TLSOptions{CAFile: pki.caFile, ServerName: "server.test"},
+		},
+		{
+			name:    "without the CA",
+			addr:    addr,
+			opts:    TLSOptions{ServerName: "server.test"},
+			wantErr: "unknown authority",
+		},
+		{
+			name:    "without the server name override",
+			addr:    addr,
+			opts:    TLSOptions{CAFile: pki.caFile},
+			wantErr: "127.0.0.1",
+		},
+		{
+			name:    "wrong server name",
+			addr:    addr,
+			opts:    TLSOptions{CAFile: pki.caFile, ServerName: "other.test"},
+			wantErr: "other.test",
+		},
+		{
+			name: "mutual TLS",
+			addr: mtlsAddr,
+			opts: TLSOptions{CAFile: pki.caFile, ServerName: "server.test",
+				CertFile: pki.clientCert, KeyFile: pki.clientKey},
+		},
+		{
+			name:    "mutual TLS without a client certificate",
+			addr:    mtlsAddr,
+			opts:    TLSOptions{CAFile: pki.caFile, ServerName: "server.test"},
+			wantErr: "certificate",
+		},
+		{
+			name: "matching SPKI pin",
+			addr: addr,
+			opts: TLSOptions{CAFile: pki.caFile, ServerName: "server.test", SPKIPin: pki.serverPin},
+		},
+		{
+			name: "mismatched SPKI pin",
+			addr: addr,
+			opts: TLSOptions{CAFile: pki.caFile, ServerName: "server.test",
+				SPKIPin: base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))},
+			wantErr: "pinned SPKI (got " + pki.serverPin + ")",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dialTLS(t, tt.addr, tt.opts)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("connected; want error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSOptionsConfigErrors(t *testing.T) {
+	pki := newTestPKI(t)
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    TLSOptions
+		wantErr string
+	}{
+		{"missing CA bundle", TLSOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, "read CA bundle"},
+		{"CA bundle without certificates", TLSOptions{CAFile: empty}, "contains no PEM certificates"},
+		{"certificate without key", TLSOptions{CertFile: pki.clientCert}, "both a client certificate and a key"},
+		{"key without certificate", TLSOptions{KeyFile: pki.clientKey}, "both a client certificate and a key"},
+		{"key that does not match", TLSOptions{CertFile: pki.caFile, KeyFile: pki.clientKey}, "load client certificate"},
+		{"pin that is not base64", TLSOptions{SPKIPin: "not base64!"}, "SPKI pin"},
+		{"pin of the wrong length", TLSOptions{SPKIPin: base64.StdEncoding.EncodeToString([]byte("short"))}, "SPKI pin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.opts.Config()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExplainTLSError(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := pki.serveTLS(t, false)
+	mtlsAddr := pki.serveTLS(t, true)
+
+	// A plain TCP server that answers the handshake with an HTTP response
+	plain, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { plain.Close() })
+	go func() {
+		for {
+			conn, err := plain.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	tests := []struct {
+		name string
+		addr string
+		opts TLSOptions
+		hint string
+	}{
+		{"unknown authority", addr, TLSOptions{ServerName: "server.test"}, "set a CA bundle"},
+		{"name mismatch", addr, TLSOptions{CAFile: pki.caFile, ServerName: "other.test"}, "set a server-name override"},
+		{"plaintext server", plain.Addr().String(), TLSOptions{CAFile: pki.caFile, ServerName: "server.test"}, "use --insecure"},
+		{"pin mismatch", addr, TLSOptions{CAFile: pki.caFile, ServerName: "server.test",
+			SPKIPin: base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))}, "pin is wrong"},
+		{"client certificate required", mtlsAddr, TLSOptions{CAFile: pki.caFile, ServerName: "server.test"}, "mutual TLS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dialTLS(t, tt.addr, tt.opts)
+			if err == nil {
+				t.Fatal("connected; want a handshake error")
+			}
+			explained := ExplainTLSError(tt.addr, err)
+			if !strings.Contains(explained.Error(), tt.hint) {
+				t.Fatalf("%q has no hint %q", explained, tt.hint)
+			}
+			if !errors.Is(explained, err) {
+				t.Fatalf("%q does not wrap the handshake error", explained)
+			}
+			if !isTLSError(err) {
+				t.Fatalf("isTLSError(%q) = false", err)
+			}
+		})
+	}
+
+	other := errors.New("connection refused")
+	if got := ExplainTLSError("server", other); got.Error() != "TLS handshake with server failed: connection refused" {
+		t.Fatalf("error without a known cause: %q", got)
+	}
+}
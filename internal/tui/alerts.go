@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/gdamore/tcell/v2"
+)
+
+// TerminalAlerts rings the bell and raises desktop notifications through
+// terminal escape sequences. They are queued and written from the draw
+// loop, between tview's drawing and tcell flushing the frame, so they
+// never land inside a screen update. Being hooked into the application
+// rather than a page, they also go out while the session is locked, but
+// then without the notification's content.
+type TerminalAlerts struct {
+	mode   internal.DesktopMode
+	tmux   bool
+	kinds  []string    // notification kinds that raise a desktop notification
+	locked func() bool // reports whether the lock screen is up
+
+	mu      sync.Mutex
+	bell    bool
+	pending []string
+}
+
+// NewTerminalAlerts resolves the auto mode from the environment
+func NewTerminalAlerts(mode internal.DesktopMode, tmux bool, kinds []string, getenv func(string) string, locked func() bool) *TerminalAlerts {
+	if mode == internal.DesktopAuto {
+		mode = internal.DetectDesktopMode(getenv)
+	}
+	return &TerminalAlerts{mode: mode, tmux: tmux, kinds: kinds, locked: locked}
+}
+
+// Mode returns the resolved desktop notification mode
+func (a *TerminalAlerts) Mode() internal.DesktopMode {
+	return a.mode
+}
+
+// Bell rings the terminal bell on the next draw
+func (a *TerminalAlerts) Bell() {
+	a.mu.Lock()
+	a.bell = true
+	a.mu.Unlock()
+}
+
+// Wants reports whether the notification raises a desktop notification:
+// priority ones always do, others when their kind is configured
+func (a *TerminalAlerts) Wants(n Notification, decision internal.Decision) bool {
+	if a.mode == internal.DesktopOff || !decision.Interrupts() {
+		return false
+	}
+	return decision.Action == internal.ActionPriority || slices.Contains(a.kinds, n.Kind())
+}
+
+// Notify raises a desktop notification on the next draw. The bell rings
+// with it, which also makes tmux flag the window. While the session is
+// locked the body is replaced, as the lock screen hides it too.
+func (a *TerminalAlerts) Notify(title, body string) {
+	if a.mode == internal.DesktopOff {
+		return
+	}
+	if a.locked != nil && a.locked() {
+		body = "New notification"
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.bell = true
+	if seq := internal.DesktopSequence(a.mode, a.tmux, title, body); seq != "" {
+		a.pending = append(a.pending, seq)
+	}
+}
+
+// Draw writes the queued alerts; installed with SetAfterDrawFunc
+func (a *TerminalAlerts) Draw(screen tcell.Screen) {
+	a.mu.Lock()
+	bell, pending := a.bell, a.pending
+	a.bell, a.pending = false, nil
+	a.mu.Unlock()
+
+	if len(pending) > 0 {
+		if tty, ok := screen.Tty(); ok {
+			for _, seq := range pending {
+				tty.Write([]byte(seq))
+			}
+		}
+	}
+	if bell {
+		screen.Beep()
+	}
+}
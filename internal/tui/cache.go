@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	proto "github.com/codek7-services/codek7-tui/pkg/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+const cacheVersion = 3
+
+// LibraryCache keeps a copy of each user's library on disk so it can be
+// browsed while the server is unreachable. A cache without a directory
+// stores nothing.
+type LibraryCache struct {
+	dir string
+}
+
+func NewLibraryCache(dir string) *LibraryCache {
+	return &LibraryCache{dir: dir}
+}
+
+// CachedLibrary is one user's library as of SavedAt
+type CachedLibrary struct {
+	SavedAt       time.Time
+	User          *proto.UserResponse
+	Videos        []*proto.VideoMetadataResponse
+	Notifications []Notification
+}
+
+type cacheFile struct {
+	Version       int               `json:"version"`
+	SavedAt       time.Time         `json:"saved_at"`
+	User          json.RawMessage   `json:"user"`
+	Videos        []json.RawMessage `json:"videos"`
+	Notifications []Notification    `json:"notifications"`
+}
+
+func (c *LibraryCache) path(username string) string {
+	return filepath.Join(c.dir, "library-"+url.PathEscape(username)+".json")
+}
+
+// Save replaces the cached library of lib.User
+func (c *LibraryCache) Save(lib *CachedLibrary) error {
+	if c == nil || c.dir == "" || lib.User == nil {
+		return nil
+	}
+
+	// Never write the password the server echoes back
+	user := gproto.Clone(lib.User).(*proto.UserResponse)
+	user.Password = ""
+
+	file := cacheFile{
+		Version:       cacheVersion,
+		SavedAt:       lib.SavedAt,
+		Notifications: lib.Notifications,
+	}
+	var err error
+	if file.User, err = protojson.Marshal(user); err != nil {
+		return err
+	}
+	for _, video := range lib.Videos {
+		data, err := protojson.Marshal(video)
+		if err != nil {
+			return err
+		}
+		file.Videos = append(file.Videos, data)
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	// Write then rename so a crash never leaves a truncated cache
+	path := c.path(user.Username)
+	tmp, err := os.CreateTemp(c.dir, ".library-*")
+	if err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load returns the cached library for username, or nil if there is none
+func (c *LibraryCache) Load(username string) (*CachedLibrary, error) {
+	if c == nil || c.dir == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(c.path(username))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+	if file.Version != cacheVersion {
+		// Written by another version; refetch instead. Older versions
+		// also kept a password hash, which should not linger on disk.
+		os.Remove(c.path(username))
+		return nil, nil
+	}
+
+	lib := &CachedLibrary{
+		SavedAt:       file.SavedAt,
+		User:          &proto.UserResponse{},
+		Notifications: file.Notifications,
+	}
+	if err := protojson.Unmarshal(file.User, lib.User); err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+	for _, raw := range file.Videos {
+		video := &proto.VideoMetadataResponse{}
+		if err := protojson.Unmarshal(raw, video); err != nil {
+			return nil, fmt.Errorf("read cache: %w", err)
+		}
+		lib.Videos = append(lib.Videos, video)
+	}
+	return lib, nil
+}
+
+// lastUserFile names the user whose library may be opened offline
+const lastUserFile = "last-user"
+
+// SetLastUser records who logged in last. Only that user's library is
+// offered when the app starts without a server.
+func (c *LibraryCache) SetLastUser(username string) error {
+	if c == nil || c.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, ".last-user-*")
+	if err != nil {
+		return fmt.Errorf("write last user: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(username); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write last user: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write last user: %w", err)
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.dir, lastUserFile))
+}
+
+// LastUser returns who logged in last, or "" if nobody has
+func (c *LibraryCache) LastUser() string {
+	if c == nil || c.dir == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, lastUserFile))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// LoadLastUser returns the cached library of the last user to log in, or
+// nil if there is none. With a username, it is also nil for anyone else.
+func (c *LibraryCache) LoadLastUser(username string) (*CachedLibrary, error) {
+	last := c.LastUser()
+	if last == "" || (username != "" && username != last) {
+		return nil, nil
+	}
+	lib, err := c.Load(last)
+	if err != nil || lib == nil || lib.User.GetUsername() != last {
+		return nil, err
+	}
+	return lib, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/codek7-services/codek7-tui/internal"
 	proto "github.com/codek7-services/codek7-tui/pkg/pb"
 )
 
@@ -51,24 +52,9 @@ func (v *Views) EnableDemoMode() {
 
 	// Add some demo notifications
 	demoNotifications := []Notification{
-		{
-			ID:      "notif-1",
-			Type:    "upload",
-			Message: "Video 'My First Video' uploaded successfully",
-			Time:    time.Now().Add(-2 * time.Hour).Format("15:04:05"),
-		},
-		{
-			ID:      "notif-2",
-			Type:    "system",
-			Message: "Welcome to CodeK7! Your account is ready.",
-			Time:    time.Now().Add(-1 * time.Hour).Format("15:04:05"),
-		},
-		{
-			ID:      "notif-3",
-			Type:    "upload",
-			Message: "Video 'Advanced Features Demo' processing complete",
-			Time:    time.Now().Add(-30 * time.Minute).Format("15:04:05"),
-		},
+		demoNotification(internal.KindUploadReceived, "video-1", "Video 'My First Video' uploaded successfully", 2*time.Hour),
+		demoNotification(internal.KindSystem, "", "Welcome to CodeK7! Your account is ready.", time.Hour),
+		demoNotification(internal.KindVideoReady, "video-3", "Video 'Advanced Features Demo' processing complete", 30*time.Minute),
 	}
 
 	for _, notif := range demoNotifications {
@@ -79,6 +65,14 @@ func (v *Views) EnableDemoMode() {
 	v.ShowDashboardView()
 }
 
+func demoNotification(kind internal.EventKind, videoID, message string, age time.Duration) Notification {
+	n := newNotification(kind, videoID, message)
+	n.Event.ID = fmt.Sprintf("demo-%s-%d", kind, age/time.Minute)
+	n.Event.Timestamp = time.Now().Add(-age)
+	n.Received = n.Event.Timestamp
+	return n
+}
+
 // Mock gRPC client for demo purposes
 type MockRepoServiceClient struct{}
 
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	proto "github.com/codek7-services/codek7-tui/pkg/pb"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// detailActivityLimit is how many notifications the detail page lists
+const detailActivityLimit = 20
+
+// findVideo returns the video from the loaded library
+func (v *Views) findVideo(videoID string) *proto.VideoMetadataResponse {
+	for _, video := range v.State.GetVideos() {
+		if video.Id == videoID {
+			return video
+		}
+	}
+	return nil
+}
+
+// Video Detail View; back is where ESC returns to
+func (v *Views) ShowVideoDetailView(videoID string, back func()) {
+	if !v.State.IsLoggedIn() {
+		v.showMessage("Please login first!")
+		return
+	}
+
+	viewCtx := v.enterView()
+
+	text := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true)
+	video := v.findVideo(videoID)
+	loading := false
+	var activity string
+	render := func() {
+		text.SetText(v.videoDetail(videoID, video, loading) + activity)
+		text.SetTitle(v.viewTitle("🎬 Video Details"))
+	}
+	v.liveUpdate = func() {
+		if latest := v.findVideo(videoID); latest != nil {
+			video = latest // refreshed in the background
+		}
+		activity = v.videoActivity(videoID)
+		render()
+	}
+
+	// Videos missing from the library, e.g. one just uploaded, come from the server
+	if client := v.State.GetGRPCClient(); video == nil && client != nil {
+		offline, _ := v.State.OfflineSince()
+		if !offline {
+			loading = true
+			timeout := v.Config.Timeouts.GetVideo
+			go func() {
+				ctx, cancel := rpcContext(viewCtx, timeout)
+				defer cancel()
+				fetched, err := client.GetVideoByID(ctx, &proto.GetVideoRequest{VideoId: videoID})
+				v.Draws.Queue(func() {
+					if viewCtx.Err() != nil {
+						return
+					}
+					loading = false
+					if err == nil {
+						video = fetched
+					} else if isTimeout(err) {
+						v.showRPCError("Loading video", timeout, err)
+					}
+					v.refreshLive()
+				})
+			}()
+		}
+	}
+	v.refreshLive()
+	// Ticks keep stage durations current; the activity only changes with
+	// live updates, so it is not looked up again
+	v.tickLive(viewCtx, time.Second, func() {
+		v.refreshHeader()
+		render()
+	})
+
+	text.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			back()
+		}
+	})
+	text.SetBorder(true).SetTitleAlign(tview.AlignCenter)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(text, 0, 1, true).
+		AddItem(tview.NewTextView().
+			SetText("Press ESC to go back | Use arrow keys to scroll").
+			SetTextAlign(tview.AlignCenter), 1, 0, false)
+
+	v.Pages.AddAndSwitchToPage("video", flex, true)
+}
+
+// videoDetail renders a video and its processing timeline
+func (v *Views) videoDetail(videoID string, video *proto.VideoMetadataResponse, loading bool) string {
+	var b strings.Builder
+	switch {
+	case video != nil:
+		fmt.Fprintf(&b, "[::b]%s[::-]\n\n", tview.Escape(video.Title))
+		fmt.Fprintf(&b, "🆔 ID: %s\n", tview.Escape(video.Id))
+		fmt.Fprintf(&b, "📄 File: %s\n", tview.Escape(video.FileName))
+		fmt.Fprintf(&b, "📅 Created: %s\n", tview.Escape(video.CreatedAt))
+		if video.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", tview.Escape(video.Description))
+		}
+	case loading:
+		fmt.Fprintf(&b, "⏳ Loading video %s...\n", tview.Escape(videoID))
+	default:
+		fmt.Fprintf(&b, "🆔 ID: %s\n\n⚠️ This video is not in your library.\n", tview.Escape(videoID))
+	}
+
+	b.WriteString("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n⏱️ Processing\n\n")
+	b.WriteString(v.videoTimeline(videoID))
+	return b.String()
+}
+
+// videoActivity renders the latest notifications about a video
+func (v *Views) videoActivity(videoID string) string {
+	var b strings.Builder
+	b.WriteString("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n📡 Activity\n\n")
+	activity, more := v.notificationPage(0, detailActivityLimit, func(n Notification) bool {
+		return n.Event.VideoID == videoID
+	})
+	if len(activity) == 0 {
+		b.WriteString("No notifications about this video\n")
+	}
+	for _, n := range activity {
+		fmt.Fprintf(&b, "%s  %s %s\n", n.Time().Local().Format("Jan 2 15:04:05"),
+			kindIcon(n.Kind()), tview.Escape(notificationText(n)))
+	}
+	if more {
+		b.WriteString("...older entries are in the notification center\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"sync"
+
+	"github.com/rivo/tview"
+)
+
+// drawQueueSize is how many updates can wait before Queue blocks
+const drawQueueSize = 100
+
+// DrawQueue hands updates from background goroutines, timers and tickers
+// to the UI goroutine, redrawing after each. Unlike calling tview's
+// QueueUpdateDraw directly, queuing never blocks once the application has
+// stopped: the update is dropped, so work finishing late does not hang.
+type DrawQueue struct {
+	app     *tview.Application
+	updates chan func()
+	stopped chan struct{}
+	stop    sync.Once
+}
+
+func NewDrawQueue(app *tview.Application) *DrawQueue {
+	q := &DrawQueue{
+		app:     app,
+		updates: make(chan func(), drawQueueSize),
+		stopped: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Queue runs fn on the UI goroutine and redraws. Safe to call from any
+// goroutine but the UI one.
+func (q *DrawQueue) Queue(fn func()) {
+	select {
+	case q.updates <- fn:
+	case <-q.stopped:
+	}
+}
+
+// Stop drops updates from now on; called once the application has stopped
+func (q *DrawQueue) Stop() {
+	q.stop.Do(func() { close(q.stopped) })
+}
+
+// run is the only goroutine that hands updates to tview, so at most it
+// is left waiting on an application that stopped
+func (q *DrawQueue) run() {
+	for {
+		select {
+		case <-q.stopped:
+			return
+		case fn := <-q.updates:
+			q.app.QueueUpdateDraw(fn)
+		}
+	}
+}
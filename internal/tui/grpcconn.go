@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rivo/tview"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// GRPCMonitor follows the connectivity state of the gRPC connection and
+// publishes changes to the UI
+type GRPCMonitor struct {
+	conn  *grpc.ClientConn
+	draws *DrawQueue
+
+	mu       sync.RWMutex
+	state    connectivity.State
+	since    time.Time
+	onChange func(connectivity.State)
+}
+
+// NewGRPCMonitor watches conn; a nil conn means the client could not be
+// created and is reported as shut down
+func NewGRPCMonitor(conn *grpc.ClientConn, draws *DrawQueue) *GRPCMonitor {
+	state := connectivity.Shutdown
+	if conn != nil {
+		state = conn.GetState()
+	}
+	return &GRPCMonitor{
+		conn:  conn,
+		draws: draws,
+		state: state,
+		since: time.Now(),
+	}
+}
+
+// SetStateHandler registers a callback for state changes; it runs on the UI
+// goroutine
+func (m *GRPCMonitor) SetStateHandler(fn func(connectivity.State)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = fn
+}
+
+// Start connects eagerly and watches state changes in the background
+func (m *GRPCMonitor) Start() {
+	if m.conn == nil {
+		return
+	}
+	m.conn.Connect()
+
+	go func() {
+		for {
+			state := m.conn.GetState()
+			m.setState(state)
+			if state == connectivity.Shutdown {
+				return
+			}
+			m.conn.WaitForStateChange(context.Background(), state)
+		}
+	}()
+}
+
+func (m *GRPCMonitor) setState(state connectivity.State) {
+	m.mu.Lock()
+	if state == m.state {
+		m.mu.Unlock()
+		return
+	}
+	m.state = state
+	m.since = time.Now()
+	fn := m.onChange
+	m.mu.Unlock()
+
+	if state == connectivity.TransientFailure {
+		slog.Warn("gRPC connection failed; retrying", "target", m.conn.Target())
+	}
+	if fn != nil {
+		m.draws.Queue(func() {
+			fn(state)
+		})
+	}
+}
+
+// State returns the current connectivity state and when it was entered
+func (m *GRPCMonitor) State() (connectivity.State, time.Time) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state, m.since
+}
+
+// Reconnect skips any pending backoff and dials the server right away
+func (m *GRPCMonitor) Reconnect() bool {
+	if m.conn == nil {
+		return false
+	}
+	m.conn.ResetConnectBackoff()
+	m.conn.Connect()
+	return true
+}
+
+// WaitReady blocks until the connection is ready or ctx is done
+func (m *GRPCMonitor) WaitReady(ctx context.Context) error {
+	if m.conn == nil {
+		return fmt.Errorf("gRPC client is not initialized")
+	}
+	for {
+		state := m.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			m.conn.Connect()
+		case connectivity.Shutdown:
+			return fmt.Errorf("gRPC connection to %s is shut down", m.conn.Target())
+		}
+		if !m.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("server %s not ready (last state: %s)", m.conn.Target(), describeGRPCState(state))
+		}
+	}
+}
+
+// describeGRPCState renders a connectivity state for the UI
+func describeGRPCState(state connectivity.State) string {
+	switch state {
+	case connectivity.Ready:
+		return "✅ Ready"
+	case connectivity.Connecting:
+		return "🔄 Connecting..."
+	case connectivity.Idle:
+		return "💤 Idle"
+	case connectivity.TransientFailure:
+		return "⚠️ Unavailable, retrying"
+	}
+	return "❌ Not connected"
+}
+
+func (v *Views) SetGRPCMonitor(m *GRPCMonitor) {
+	v.GRPC = m
+	m.SetStateHandler(func(state connectivity.State) {
+		v.handleServerState(state)
+		v.refreshLive()
+	})
+}
+
+// grpcStatus renders the server connection line for status panels
+func (v *Views) grpcStatus() string {
+	if v.GRPC == nil {
+		return describeGRPCState(connectivity.Shutdown)
+	}
+	state, since := v.GRPC.State()
+	if state == connectivity.Ready {
+		return describeGRPCState(state)
+	}
+	return fmt.Sprintf("%s (since %s)", describeGRPCState(state), describeAge(since))
+}
+
+// reconnectServer retries the gRPC connection now instead of waiting out
+// the backoff
+func (v *Views) reconnectServer() {
+	if v.GRPC == nil || !v.GRPC.Reconnect() {
+		v.showMessage("❌ gRPC client not initialized")
+		return
+	}
+	v.refreshLive()
+}
+
+// ShowPreflightView waits for the server before showing the login form
+func (v *Views) ShowPreflightView() {
+	viewCtx := v.enterView()
+
+	text := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf("⏳ Connecting to %s...", v.Config.GRPC.Addr))
+	text.SetBorder(true).SetTitle("🛰️ Server Check")
+	v.Pages.AddAndSwitchToPage("preflight", text, true)
+
+	timeout := v.Config.Timeouts.Connect
+	go func() {
+		ctx, cancel := rpcContext(viewCtx, timeout)
+		defer cancel()
+		err := v.GRPC.WaitReady(ctx)
+
+		var offline *CachedLibrary
+		if err != nil {
+			var loadErr error
+			if offline, loadErr = v.Cache.LoadLastUser(""); loadErr != nil {
+				slog.Warn("Loading library cache failed", "err", loadErr)
+			}
+		}
+
+		v.Draws.Queue(func() {
+			if viewCtx.Err() != nil {
+				return
+			}
+			if err == nil {
+				v.Pages.RemovePage("preflight")
+				v.ShowLoginView()
+				return
+			}
+
+			text := fmt.Sprintf("❌ %v\n\nThe server did not become ready within %s.", err, timeout)
+			buttons := []string{"Retry", "Continue", "Quit"}
+			if offline != nil {
+				text += "\n\n" + offlinePrompt(offline)
+				buttons = []string{"Retry", "Open offline", "Continue", "Quit"}
+			}
+			modal := tview.NewModal().
+				SetText(text).
+				AddButtons(buttons).
+				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+					v.Pages.RemovePage("preflight")
+					switch buttonLabel {
+					case "Retry":
+						v.GRPC.Reconnect()
+						v.ShowPreflightView()
+					case "Open offline":
+						v.openReadOnly(offline)
+					case "Continue":
+						v.showMain()
+					default:
+						v.App.Stop()
+					}
+				})
+			v.Pages.AddAndSwitchToPage("preflight", modal, true)
+		})
+	}()
+}
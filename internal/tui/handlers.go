@@ -3,6 +3,7 @@ package tui
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"time"
 
@@ -28,30 +29,77 @@ func (v *Views) processLogin(username, password string) {
 		return
 	}
 
-	// Call GetUser (which acts like login in your current setup)
-	user, err := client.GetUser(context.TODO(), &proto.GetUserRequest{
-		Username: username,
-		Password: password,
-	})
-	if err != nil {
-		v.showError(err)
-		return
-	}
+	viewCtx := v.currentView()
+	timeout := v.Config.Timeouts.Login
+	v.showMessage("⏳ Logging in...")
+
+	go func() {
+		ctx, cancel := rpcContext(viewCtx, timeout)
+		defer cancel()
+
+		// Call GetUser (which acts like login in your current setup)
+		user, err := client.GetUser(ctx, &proto.GetUserRequest{
+			Username: username,
+			Password: password,
+		})
+
+		// Read what is on disk here rather than on the UI goroutine.
+		// Without a server, the last user may still browse their cache.
+		var cached *CachedLibrary
+		var history []Notification
+		var offline *CachedLibrary
+		switch {
+		case err == nil:
+			if err := v.Cache.SetLastUser(user.Username); err != nil {
+				slog.Warn("Recording last user failed", "err", err)
+			}
+			cached = v.loadCache(user)
+			history = v.loadHistory(user.Id)
+		case isUnavailable(err):
+			var loadErr error
+			if offline, loadErr = v.Cache.LoadLastUser(username); loadErr != nil {
+				slog.Warn("Loading library cache failed", "err", loadErr)
+			}
+		}
+
+		v.Draws.Queue(func() {
+			if viewCtx.Err() != nil {
+				return // user left the login view
+			}
+			v.Pages.RemovePage("message")
+			if offline != nil {
+				v.offerReadOnly(offline)
+				return
+			}
+			if err != nil {
+				v.showRPCError("Login", timeout, err)
+				return
+			}
 
-	v.State.SetUser(user)
-	v.loadUserVideos() // Load videos after login
-	v.ShowDashboardView()
-	v.showMessage("Login successful!")
+			v.State.SetUser(user)
+			v.State.SetCredentials(password)
+			v.applyCache(cached)
+			v.applyHistory(history)
+			// Real-time notifications follow the session automatically
+			if v.WSManager != nil {
+				v.WSManager.Connect(user.Id)
+			}
+			v.ShowDashboardView() // loads the user's videos
+			v.Outbox.Drain()      // uploads queued in an earlier offline session
+			v.showMessage("Login successful!")
+		})
+	}()
 }
 
 func (v *Views) handleRegister() {
 	// We'll get form data through stored references
-	v.processRegister("", "", "") // Placeholder
+	v.processRegister("", "", "", "") // Placeholder
 }
 
-func (v *Views) processRegister(username, email, password string) {
-	if username == "" || email == "" || password == "" {
-		v.showMessage("Please fill in all fields")
+func (v *Views) processRegister(username, email, password, confirm string) {
+	// The register form shows these inline; this guards other callers
+	if errs := validateRegistration(username, email, password, confirm); len(errs) > 0 {
+		v.showMessage(registrationErrors(errs))
 		return
 	}
 
@@ -61,18 +109,34 @@ func (v *Views) processRegister(username, email, password string) {
 		return
 	}
 
-	_, err := client.CreateUser(context.TODO(), &proto.CreateUserRequest{
-		Username: username,
-		Email:    email,
-		Password: password,
-	})
-	if err != nil {
-		v.showError(err)
-		return
-	}
+	viewCtx := v.currentView()
+	timeout := v.Config.Timeouts.Register
+	v.showMessage("⏳ Creating account...")
+
+	go func() {
+		ctx, cancel := rpcContext(viewCtx, timeout)
+		defer cancel()
+
+		_, err := client.CreateUser(ctx, &proto.CreateUserRequest{
+			Username: username,
+			Email:    email,
+			Password: password,
+		})
 
-	v.showMessage("Registration successful! Please login.")
-	v.ShowLoginView()
+		v.Draws.Queue(func() {
+			if viewCtx.Err() != nil {
+				return // user left the register view
+			}
+			v.Pages.RemovePage("message")
+			if err != nil {
+				v.showRPCError("Registration", timeout, err)
+				return
+			}
+
+			v.ShowLoginView()
+			v.showMessage("Registration successful! Please login.")
+		})
+	}()
 }
 
 func (v *Views) handleUpload() {
@@ -97,10 +161,10 @@ func (v *Views) processUpload(filePath, title, description string) {
 		return
 	}
 
-	// Check file size (limit to 500MB as mentioned in server code)
-	maxSize := int64(500 * 1024 * 1024) // 500MB
+	// Check file size against the configured transfer limit
+	maxSize := int64(v.Config.Transfer.MaxUploadMB) * 1024 * 1024
 	if fileInfo.Size() > maxSize {
-		v.showMessage(fmt.Sprintf("❌ File too large: %.2f MB (max 500MB)", float64(fileInfo.Size())/(1024*1024)))
+		v.showMessage(fmt.Sprintf("❌ File too large: %.2f MB (max %dMB)", float64(fileInfo.Size())/(1024*1024), v.Config.Transfer.MaxUploadMB))
 		return
 	}
 
@@ -116,6 +180,12 @@ func (v *Views) processUpload(filePath, title, description string) {
 		return
 	}
 
+	// Keep the upload for later while the server is unreachable
+	if offline, _ := v.State.OfflineSince(); offline {
+		v.queueUpload(filePath, title, description)
+		return
+	}
+
 	// Show detailed progress message
 	v.showMessage(fmt.Sprintf("📤 Uploading video...\n\n"+
 		"📁 File: %s\n"+
@@ -128,66 +198,116 @@ func (v *Views) processUpload(filePath, title, description string) {
 		user.Username))
 
 	go func() {
-		err := internal.UploadVideo(client, filePath, title, description, user.Id)
-		v.App.QueueUpdateDraw(func() {
+		// Uploads are transfers, not tied to the upload view
+		ctx, cancel := rpcContext(context.Background(), v.Config.Timeouts.Upload)
+		err := internal.UploadVideo(ctx, client, filePath, title, description, user.Id, v.Config.Transfer.ChunkSizeKB*1024)
+		cancel()
+		if err == nil {
+			v.loadUserVideos(context.Background())
+		}
+
+		if isUnavailable(err) {
+			v.State.SetOffline(true)
+			v.Draws.Queue(func() {
+				v.Pages.RemovePage("message")
+				v.queueUpload(filePath, title, description)
+			})
+			return
+		}
+
+		v.Draws.Queue(func() {
 			if err != nil {
-				v.showError(fmt.Errorf("Upload failed: %v", err))
-			} else {
-				// Success message with next steps
-				v.showMessage("✅ Video uploaded successfully!\n\n" +
-					"🎯 Your video is now being processed.\n" +
-					"📡 You'll receive real-time notifications when ready.\n" +
-					"🔄 The video list will be updated automatically.")
-
-				// Add a notification about the upload
-				v.State.AddNotification(Notification{
-					ID:      fmt.Sprintf("upload-%d", time.Now().Unix()),
-					Type:    "upload",
-					Message: fmt.Sprintf("Video '%s' uploaded successfully", title),
-					Time:    time.Now().Format("15:04:05"),
-				})
-
-				// Refresh data and return to dashboard
-				v.loadUserVideos()
-
-				// Auto-return to dashboard after showing success
-				time.Sleep(2 * time.Second)
-				v.App.QueueUpdateDraw(func() {
-					v.Pages.RemovePage("message")
-					v.ShowDashboardView()
-				})
+				if isTimeout(err) {
+					v.showRPCError("Upload", v.Config.Timeouts.Upload, err)
+				} else {
+					v.showError(fmt.Errorf("Upload failed: %v", err))
+				}
+				return
 			}
+
+			// Success message with next steps
+			v.showMessage("✅ Video uploaded successfully!\n\n" +
+				"🎯 Your video is now being processed.\n" +
+				"📡 You'll receive real-time notifications when ready.\n" +
+				"🔄 The video list will be updated automatically.")
+
+			// Add a notification about the upload
+			notif := newNotification(internal.KindUploadReceived, "",
+				fmt.Sprintf("Video '%s' uploaded successfully", title))
+			v.State.AddNotification(notif)
+			v.recordNotification(notif)
+		})
+		if err != nil {
+			return
+		}
+
+		// Auto-return to dashboard after showing success
+		time.Sleep(2 * time.Second)
+		v.Draws.Queue(func() {
+			v.Pages.RemovePage("message")
+			v.ShowDashboardView()
 		})
 	}()
 }
 
 func (v *Views) handleLogout() {
+	v.Outbox.Cancel() // queued uploads wait for the user's next login
 	v.State.Logout()
 	if v.WSManager != nil {
 		v.WSManager.Disconnect()
 	}
-	v.Pages.SwitchToPage("main")
+	v.showMain()
 	v.showMessage("Logged out successfully!")
 }
 
-func (v *Views) loadUserVideos() {
+// loadUserVideos fetches the user's library; it blocks, so call it off the
+// UI goroutine
+func (v *Views) loadUserVideos(parent context.Context) error {
 	user := v.State.GetUser()
-	if user == nil {
-		return
+	if user == nil || v.State.IsReadOnly() {
+		return nil
 	}
 
 	client := v.State.GetGRPCClient()
 	if client == nil {
-		return
+		return nil
 	}
 
-	videos, err := client.GetUserVideos(context.TODO(), &proto.GetUserVideosRequest{
+	ctx, cancel := rpcContext(parent, v.Config.Timeouts.ListVideos)
+	defer cancel()
+
+	videos, err := client.GetUserVideos(ctx, &proto.GetUserVideosRequest{
 		UserId: user.Id,
 	})
 	if err != nil {
-		// Don't show error popup, just log it
-		return
+		if isUnavailable(err) {
+			v.State.SetOffline(true)
+		}
+		return err
 	}
 
 	v.State.SetVideos(videos.Videos)
+	v.State.SetSynced(time.Now())
+	v.saveCache()
+	return nil
+}
+
+// reloadVideos refreshes the library in the background and runs onDone on
+// the UI goroutine, unless the view that asked for it has been left
+func (v *Views) reloadVideos(viewCtx context.Context, onDone func()) {
+	go func() {
+		err := v.loadUserVideos(viewCtx)
+		v.Draws.Queue(func() {
+			if viewCtx.Err() != nil {
+				return
+			}
+			// Other failures keep the cached list; only timeouts are worth a popup
+			if isTimeout(err) {
+				v.showRPCError("Loading videos", v.Config.Timeouts.ListVideos, err)
+			}
+			if onDone != nil {
+				onDone()
+			}
+		})
+	}()
 }
@@ -0,0 +1,405 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxNotifications is how many notifications are kept in memory
+const maxNotifications = 50
+
+// NotificationHistory appends every notification to a JSON-lines file per
+// user, so it outlives the in-memory list, logout and restarts. A history
+// without a directory stores nothing. Each user's file is read once and
+// then kept in memory alongside it, so paging and searching never go back
+// to the disk. Marking read and deleting append a change line instead of
+// rewriting the file; Prune folds the changes in.
+type NotificationHistory struct {
+	dir        string
+	retention  time.Duration // 0 keeps entries forever
+	maxEntries int           // 0 for no limit
+
+	mu      sync.Mutex
+	entries map[string][]Notification // user ID -> history, oldest first
+	changes map[string]int            // user ID -> change lines in the file
+}
+
+// historyChange is a line recording a change to earlier entries
+type historyChange struct {
+	Op   string `json:"op"`
+	Key  string `json:"key,omitempty"`
+	Read bool   `json:"read,omitempty"`
+}
+
+const (
+	opRead    = "read" // sets Read on the entry with Key
+	opReadAll = "read_all"
+	opDelete  = "delete"
+)
+
+// historyLine reads either kind of line
+type historyLine struct {
+	Notification
+	Op  string `json:"op"`
+	Key string `json:"key"`
+}
+
+// apply makes the change to entries, in place
+func (c historyChange) apply(entries []Notification) []Notification {
+	switch c.Op {
+	case opRead:
+		for i := range entries {
+			if entries[i].Key() == c.Key {
+				entries[i].Read = c.Read
+			}
+		}
+	case opReadAll:
+		for i := range entries {
+			entries[i].Read = true
+		}
+	case opDelete:
+		return slices.DeleteFunc(entries, func(n Notification) bool {
+			return n.Key() == c.Key
+		})
+	}
+	return entries
+}
+
+func NewNotificationHistory(dir string, retention time.Duration, maxEntries int) *NotificationHistory {
+	return &NotificationHistory{
+		dir:        dir,
+		retention:  retention,
+		maxEntries: maxEntries,
+		entries:    map[string][]Notification{},
+		changes:    map[string]int{},
+	}
+}
+
+// Enabled reports whether notifications are stored
+func (h *NotificationHistory) Enabled() bool {
+	return h != nil && h.dir != ""
+}
+
+func (h *NotificationHistory) path(userID string) string {
+	return filepath.Join(h.dir, url.PathEscape(userID)+".jsonl")
+}
+
+// Append adds a notification to the end of the user's history
+func (h *NotificationHistory) Append(userID string, n Notification) error {
+	if !h.Enabled() {
+		return nil
+	}
+	line, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.appendLine(userID, line); err != nil {
+		return err
+	}
+	if entries, ok := h.entries[userID]; ok {
+		h.entries[userID] = append(entries, n)
+	}
+	return nil
+}
+
+// SetRead marks the entry with key read or unread
+func (h *NotificationHistory) SetRead(userID, key string, read bool) error {
+	return h.change(userID, historyChange{Op: opRead, Key: key, Read: read})
+}
+
+// MarkAllRead marks every entry read
+func (h *NotificationHistory) MarkAllRead(userID string) error {
+	return h.change(userID, historyChange{Op: opReadAll})
+}
+
+// Remove deletes the entry with key
+func (h *NotificationHistory) Remove(userID, key string) error {
+	return h.change(userID, historyChange{Op: opDelete, Key: key})
+}
+
+// Unread returns how many entries in the user's history are unread
+func (h *NotificationHistory) Unread(userID string) (int, error) {
+	if !h.Enabled() {
+		return 0, nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entries, err := h.load(userID)
+	unread := 0
+	for _, n := range entries {
+		if !n.Read {
+			unread++
+		}
+	}
+	return unread, err
+}
+
+// change appends a change line and applies it to the entries in memory
+func (h *NotificationHistory) change(userID string, c historyChange) error {
+	if !h.Enabled() {
+		return nil
+	}
+	line, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.appendLine(userID, line); err != nil {
+		return err
+	}
+	h.changes[userID]++
+	if entries, ok := h.entries[userID]; ok {
+		h.entries[userID] = c.apply(entries)
+	}
+	return nil
+}
+
+// appendLine adds a line to the user's file; called with h.mu held
+func (h *NotificationHistory) appendLine(userID string, line []byte) error {
+	if err := os.MkdirAll(h.dir, 0o700); err != nil {
+		return fmt.Errorf("create history directory: %w", err)
+	}
+	f, err := os.OpenFile(h.path(userID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+	return f.Close()
+}
+
+// Page returns up to limit notifications that match, newest first,
+// skipping the newest offset ones. more reports whether older ones remain.
+// A nil match takes every notification.
+func (h *NotificationHistory) Page(userID string, offset, limit int, match func(Notification) bool) (page []Notification, more bool, err error) {
+	if !h.Enabled() {
+		return nil, false, nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entries, err := h.load(userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Walk back from the newest entry until the page is full
+	skipped := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		if match != nil && !match(entries[i]) {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		if len(page) == limit {
+			return page, true, nil
+		}
+		page = append(page, entries[i])
+	}
+	return page, false, nil
+}
+
+// All returns the user's whole history, oldest first
+func (h *NotificationHistory) All(userID string) ([]Notification, error) {
+	if !h.Enabled() {
+		return nil, nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entries, err := h.load(userID)
+	return slices.Clone(entries), err
+}
+
+// Prune drops entries past the retention period and over the entry limit,
+// rewriting the file if that or folding in change lines shortens it
+func (h *NotificationHistory) Prune(userID string) error {
+	if !h.Enabled() {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entries, err := h.load(userID)
+	if err != nil {
+		return err
+	}
+
+	kept := entries
+	if h.retention > 0 {
+		cutoff := time.Now().Add(-h.retention)
+		kept = slices.DeleteFunc(slices.Clone(kept), func(n Notification) bool {
+			return n.Received.Before(cutoff)
+		})
+	}
+	if h.maxEntries > 0 && len(kept) > h.maxEntries {
+		kept = kept[len(kept)-h.maxEntries:]
+	}
+	if len(kept) == len(entries) && h.changes[userID] == 0 {
+		return nil
+	}
+	return h.write(userID, kept)
+}
+
+// load returns the user's history from memory, reading the file the first
+// time; called with h.mu held. Callers must not modify the result.
+func (h *NotificationHistory) load(userID string) ([]Notification, error) {
+	if entries, ok := h.entries[userID]; ok {
+		return entries, nil
+	}
+	entries, err := h.read(userID)
+	if err != nil {
+		return nil, err
+	}
+	h.entries[userID] = entries
+	return entries, nil
+}
+
+// read loads the user's history file, oldest first, applying change lines
+// as it goes. Lines that do not parse, such as one cut short by a crash,
+// are skipped.
+func (h *NotificationHistory) read(userID string) ([]Notification, error) {
+	f, err := os.Open(h.path(userID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Notification
+	changes := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var l historyLine
+		if err := json.Unmarshal(line, &l); err != nil {
+			slog.Warn("Skipping unreadable history entry", "err", err)
+			continue
+		}
+		if l.Op != "" {
+			entries = historyChange{Op: l.Op, Key: l.Key, Read: l.Read}.apply(entries)
+			changes++
+			continue
+		}
+		entries = append(entries, l.Notification)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	h.changes[userID] = changes
+	return entries, nil
+}
+
+// write replaces the user's history
+func (h *NotificationHistory) write(userID string, entries []Notification) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, n := range entries {
+		if err := enc.Encode(n); err != nil {
+			return err
+		}
+	}
+
+	// Write then rename so a crash never leaves a truncated history
+	tmp, err := os.CreateTemp(h.dir, ".history-*")
+	if err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path(userID)); err != nil {
+		return err
+	}
+	h.entries[userID] = entries
+	h.changes[userID] = 0
+	return nil
+}
+
+// historyUser returns the ID of the user whose history is kept, or "" for
+// demo sessions, which are not recorded
+func (v *Views) historyUser() string {
+	user := v.State.GetUser()
+	if user == nil || !v.State.HasCredentials() {
+		return ""
+	}
+	return user.Id
+}
+
+// recordNotification appends a notification to the logged-in user's
+// history
+func (v *Views) recordNotification(n Notification) {
+	userID := v.historyUser()
+	if userID == "" {
+		return
+	}
+	if err := v.History.Append(userID, n); err != nil {
+		slog.Warn("Saving notification history failed", "err", err)
+	}
+}
+
+// unreadCount counts the unread notifications in the whole history, or
+// in the recent ones kept in memory when there is no history
+func (v *Views) unreadCount() int {
+	if userID := v.historyUser(); userID != "" && v.History.Enabled() {
+		n, err := v.History.Unread(userID)
+		if err == nil {
+			return n
+		}
+		slog.Warn("Loading notification history failed", "err", err)
+	}
+	return v.State.UnreadCount()
+}
+
+// loadHistory applies retention to the user's history and returns all of
+// it. It reads the disk, so call it off the UI goroutine.
+func (v *Views) loadHistory(userID string) []Notification {
+	if !v.History.Enabled() {
+		return nil
+	}
+	if err := v.History.Prune(userID); err != nil {
+		slog.Warn("Pruning notification history failed", "err", err)
+	}
+	all, err := v.History.All(userID)
+	if err != nil {
+		slog.Warn("Loading notification history failed", "err", err)
+	}
+	return all
+}
+
+// applyHistory makes the newest history entries the in-memory list and
+// replays all of them into the processing timelines
+func (v *Views) applyHistory(all []Notification) {
+	if len(all) == 0 {
+		return // keep what the cache restored; SetUser clears other users' entries
+	}
+	v.State.SetNotifications(slices.Clone(all[max(len(all)-maxNotifications, 0):]))
+	v.State.RebuildPipelines(all)
+}
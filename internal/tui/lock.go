@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/codek7-services/codek7-tui/internal/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// IdleLocker swaps the application root for a lock screen after a period
+// without user input. Pages keep updating underneath, so transfers and the
+// WebSocket carry on while the session is locked.
+type IdleLocker struct {
+	views     *Views
+	root      tview.Primitive
+	timeout   time.Duration
+	mu        sync.Mutex
+	lastInput time.Time
+	saved     tview.Primitive // focus before locking
+	savedPage tview.Primitive // the page it was on
+	failures  int
+}
+
+func NewIdleLocker(views *Views, root tview.Primitive, timeout time.Duration) *IdleLocker {
+	return &IdleLocker{
+		views:     views,
+		root:      root,
+		timeout:   timeout,
+		lastInput: time.Now(),
+	}
+}
+
+// Touch records user activity
+func (l *IdleLocker) Touch() {
+	l.mu.Lock()
+	l.lastInput = time.Now()
+	l.mu.Unlock()
+}
+
+// Start hooks input tracking into the application and starts the idle watcher
+func (l *IdleLocker) Start() {
+	app := l.views.App
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		l.Touch()
+		if config.MatchKey(l.views.Config.Keys.Lock, event) && l.views.State.IsLoggedIn() && !l.views.State.IsLocked() {
+			l.Lock()
+			return nil
+		}
+		if config.MatchKey(l.views.Config.Keys.Dismiss, event) && l.views.Toasts != nil && l.views.Toasts.DismissSticky() {
+			return nil
+		}
+		return event
+	})
+	app.SetMouseCapture(func(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+		if action != tview.MouseMove {
+			l.Touch()
+		}
+		return event, action
+	})
+
+	if l.timeout <= 0 {
+		return
+	}
+
+	go func() {
+		interval := l.timeout / 4
+		if interval > 5*time.Second {
+			interval = 5 * time.Second
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			l.mu.Lock()
+			idle := time.Since(l.lastInput)
+			l.mu.Unlock()
+
+			state := l.views.State
+			if idle >= l.timeout && state.IsLoggedIn() && !state.IsLocked() {
+				l.views.Draws.Queue(l.Lock)
+			}
+		}
+	}()
+}
+
+// Lock replaces the visible UI with the lock screen. Must run on the UI goroutine.
+func (l *IdleLocker) Lock() {
+	state := l.views.State
+	if !state.IsLoggedIn() || state.IsLocked() {
+		return
+	}
+	if state.IsReadOnly() {
+		// No password to unlock with; the user logs in again instead
+		l.views.handleLogout()
+		return
+	}
+
+	state.SetLocked(true)
+	l.saved = l.views.App.GetFocus()
+	_, l.savedPage = l.views.Pages.GetFrontPage()
+	l.failures = 0
+	l.views.App.SetRoot(l.lockView(), true)
+}
+
+// release restores the regular UI. Focus goes back where it was unless
+// the page changed underneath, e.g. after a timed return to the dashboard;
+// then the page now in front gets it.
+func (l *IdleLocker) release() {
+	l.views.State.SetLocked(false)
+	l.Touch()
+	l.views.App.SetRoot(l.root, true)
+	_, front := l.views.Pages.GetFrontPage()
+	switch {
+	case l.saved != nil && front == l.savedPage:
+		l.views.App.SetFocus(l.saved)
+	case front != nil:
+		l.views.App.SetFocus(front)
+	}
+	l.saved, l.savedPage = nil, nil
+}
+
+func (l *IdleLocker) lockView() tview.Primitive {
+	user := l.views.State.GetUser()
+	username := "unknown"
+	if user != nil {
+		username = user.Username
+	}
+
+	prompt := "Session locked after inactivity.\nEnter your password to continue."
+	if !l.views.State.HasCredentials() {
+		prompt = "Session locked after inactivity.\nDemo session: press Unlock to continue."
+	}
+
+	info := tview.NewTextView().
+		SetText("🔒 " + username + "\n\n" + prompt).
+		SetTextAlign(tview.AlignCenter)
+
+	status := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+
+	form := tview.NewForm()
+	var password string
+	unlock := func() {
+		if l.views.State.CheckPassword(password) {
+			l.release()
+			return
+		}
+		l.failures++
+		status.SetText(fieldError("Incorrect password"))
+		if l.failures >= 5 {
+			l.release()
+			l.views.handleLogout()
+			l.views.showMessage("🔒 Too many failed unlock attempts. You have been logged out.")
+		}
+	}
+
+	form.AddPasswordField("Password", "", 30, '*', func(text string) {
+		password = text
+	}).
+		AddButton("🔓 Unlock", unlock).
+		AddButton("🚪 Logout", func() {
+			l.release()
+			l.views.handleLogout()
+		})
+	form.GetFormItem(0).(*tview.InputField).SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			unlock()
+		}
+	})
+
+	box := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(info, 4, 0, false).
+		AddItem(form, 5, 0, true).
+		AddItem(status, 1, 0, false)
+	box.SetBorder(true).SetTitle("🔒 Locked").SetTitleAlign(tview.AlignCenter)
+
+	return tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(box, 50, 0, true).
+			AddItem(nil, 0, 1, false), 12, 0, true).
+		AddItem(nil, 0, 1, false)
+}
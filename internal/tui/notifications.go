@@ -0,0 +1,394 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// notificationPageSize is how many notifications the notification center
+// loads at a time
+const notificationPageSize = 50
+
+// Type filter choices besides the event kinds
+const (
+	filterAll    = "all"
+	filterUnread = "unread"
+	filterMissed = "missed"
+)
+
+// notificationFilter narrows the notification center
+type notificationFilter struct {
+	kind  string // filterAll, filterUnread or a notification kind
+	query string // matched case-insensitively against kind, video and text
+}
+
+func (f notificationFilter) match(n Notification) bool {
+	switch f.kind {
+	case filterAll, "":
+	case filterUnread:
+		if n.Read {
+			return false
+		}
+	case filterMissed:
+		if !n.Missed {
+			return false
+		}
+	default:
+		if n.Kind() != f.kind {
+			return false
+		}
+	}
+	if f.query == "" {
+		return true
+	}
+	query := strings.ToLower(f.query)
+	for _, field := range []string{n.Kind(), n.Event.VideoID, n.Text()} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterChoices lists the type filter options
+func filterChoices() []string {
+	kinds := []internal.EventKind{
+		internal.KindUploadReceived, internal.KindProcessingQueued, internal.KindProcessingStarted, internal.KindProcessingProgress,
+		internal.KindVideoReady, internal.KindProcessingFailed, internal.KindVideoRemoved, internal.KindSystem,
+		internal.KindNotification,
+	}
+	choices := []string{filterAll, filterUnread, filterMissed}
+	for _, kind := range kinds {
+		choices = append(choices, string(kind))
+	}
+	return append(choices, kindMalformed)
+}
+
+// notificationText is the notification's text, flagged when it arrived
+// in a catch-up after the client was away
+func notificationText(n Notification) string {
+	if n.Missed {
+		return "📴 missed while offline · " + n.Text()
+	}
+	return n.Text()
+}
+
+// notificationPage returns the logged-in user's notifications that match,
+// newest first, from the history when there is one and from memory
+// otherwise
+func (v *Views) notificationPage(offset, limit int, match func(Notification) bool) ([]Notification, bool) {
+	user := v.State.GetUser()
+	if user != nil && v.History.Enabled() && v.State.HasCredentials() {
+		page, more, err := v.History.Page(user.Id, offset, limit, match)
+		if err == nil {
+			return page, more
+		}
+		slog.Warn("Loading notification history failed", "err", err)
+	}
+
+	all := v.State.GetNotifications()
+	if match != nil {
+		all = slices.DeleteFunc(all, func(n Notification) bool { return !match(n) })
+	}
+	slices.Reverse(all)
+	if offset >= len(all) {
+		return nil, false
+	}
+	end := min(offset+limit, len(all))
+	return all[offset:end], end < len(all)
+}
+
+// setNotificationRead marks one notification read or unread
+func (v *Views) setNotificationRead(n Notification, read bool) {
+	v.State.SetRead(read, n.Key())
+	if userID := v.historyUser(); userID != "" {
+		if err := v.History.SetRead(userID, n.Key(), read); err != nil {
+			slog.Warn("Updating notification history failed", "err", err)
+		}
+	}
+}
+
+func (v *Views) markAllNotificationsRead() {
+	v.State.MarkAllRead()
+	if userID := v.historyUser(); userID != "" {
+		if err := v.History.MarkAllRead(userID); err != nil {
+			slog.Warn("Updating notification history failed", "err", err)
+		}
+	}
+}
+
+func (v *Views) deleteNotification(n Notification) {
+	v.State.RemoveNotification(n.Key())
+	if userID := v.historyUser(); userID != "" {
+		if err := v.History.Remove(userID, n.Key()); err != nil {
+			slog.Warn("Updating notification history failed", "err", err)
+		}
+	}
+}
+
+// Notification Center View
+func (v *Views) ShowNotificationsView() {
+	if !v.State.IsLoggedIn() {
+		v.showMessage("Please login first!")
+		return
+	}
+
+	v.enterView()
+
+	filter := notificationFilter{kind: filterAll}
+	table := tview.NewTable().SetSelectable(true, false)
+
+	// Rows map to notifications; -1 is the "load older" row
+	var shown []Notification
+	var rows map[int]int
+	limit := notificationPageSize
+	more := false
+	focusIndex := -1 // row to select after loading older ones
+
+	selected := func() (Notification, bool) {
+		row, _ := table.GetSelection()
+		i, ok := rows[row]
+		if !ok || i < 0 {
+			return Notification{}, false
+		}
+		return shown[i], true
+	}
+
+	render := func() {
+		selectedKey := ""
+		if n, ok := selected(); ok {
+			selectedKey = n.Key()
+		}
+
+		shown, more = v.notificationPage(0, limit, filter.match)
+		rows = map[int]int{}
+		table.Clear()
+
+		if len(shown) == 0 {
+			text := "No notifications"
+			if filter.kind != filterAll || filter.query != "" {
+				text = "No notifications match the filter"
+			}
+			table.SetCell(0, 0, tview.NewTableCell(text).SetSelectable(false))
+			v.refreshHeader()
+			return
+		}
+
+		row, day, selectRow := 0, "", -1
+		for i, n := range shown {
+			if d := n.Time().Local().Format("Monday, Jan 2 2006"); d != day {
+				day = d
+				table.SetCell(row, 0, tview.NewTableCell("📅 "+day).
+					SetTextColor(v.headerColor()).SetSelectable(false))
+				row++
+			}
+
+			marker := " "
+			if !n.Read {
+				marker = "[yellow]●[-]"
+			}
+			video := ""
+			if n.Event.VideoID != "" {
+				video = "🎬 " + n.Event.VideoID
+			}
+			table.SetCell(row, 0, tview.NewTableCell(marker))
+			table.SetCell(row, 1, tview.NewTableCell(n.Time().Local().Format("15:04:05")))
+			table.SetCell(row, 2, tview.NewTableCell(kindIcon(n.Kind())+" "+tview.Escape(n.Kind())))
+			table.SetCell(row, 3, tview.NewTableCell(tview.Escape(video)))
+			table.SetCell(row, 4, tview.NewTableCell(tview.Escape(notificationText(n))).SetExpansion(1))
+			rows[row] = i
+			if i == focusIndex || (focusIndex < 0 && n.Key() == selectedKey) || selectRow < 0 {
+				selectRow = row
+			}
+			row++
+		}
+		if more {
+			table.SetCell(row, 0, tview.NewTableCell(""))
+			table.SetCell(row, 4, tview.NewTableCell(fmt.Sprintf("⬇️  Load older notifications (%d shown)", len(shown))))
+			rows[row] = -1
+		}
+		table.Select(selectRow, 0)
+		focusIndex = -1
+		v.refreshHeader()
+	}
+
+	search := tview.NewInputField().SetLabel("🔍 Search: ")
+	search.SetChangedFunc(func(text string) {
+		filter.query = text
+		limit = notificationPageSize
+		render()
+	})
+	search.SetDoneFunc(func(tcell.Key) {
+		v.App.SetFocus(table)
+	})
+
+	choices := filterChoices()
+	kinds := tview.NewDropDown().SetLabel("Type: ").SetOptions(choices, func(option string, _ int) {
+		if option == filter.kind {
+			return
+		}
+		filter.kind = option
+		limit = notificationPageSize
+		render()
+		v.App.SetFocus(table)
+	})
+	kinds.SetCurrentOption(0)
+	kinds.SetDoneFunc(func(tcell.Key) {
+		v.App.SetFocus(table)
+	})
+
+	table.SetSelectedFunc(func(row, _ int) {
+		i, ok := rows[row]
+		if !ok {
+			return
+		}
+		if i < 0 {
+			focusIndex = len(shown)
+			limit += notificationPageSize
+			render()
+			return
+		}
+		n := shown[i]
+		v.setNotificationRead(n, true)
+		if n.Event.VideoID != "" {
+			v.ShowVideoDetailView(n.Event.VideoID, v.ShowNotificationsView)
+			return
+		}
+		render()
+	})
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			v.ShowDashboardView()
+		}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDelete {
+			if n, ok := selected(); ok {
+				v.deleteNotification(n)
+				render()
+			}
+			return nil
+		}
+		switch event.Rune() {
+		case 'r':
+			if n, ok := selected(); ok {
+				v.setNotificationRead(n, !n.Read)
+				render()
+			}
+		case 'a':
+			v.markAllNotificationsRead()
+			render()
+		case 'd':
+			if n, ok := selected(); ok {
+				v.deleteNotification(n)
+				render()
+			}
+		case '/':
+			v.App.SetFocus(search)
+		case 't':
+			v.App.SetFocus(kinds)
+		case 'l':
+			v.ShowDeliveriesView()
+		case 'e':
+			v.ShowRulesView()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	// New notifications show up as they arrive
+	v.liveUpdate = render
+	render()
+
+	table.SetBorder(true).SetTitle(v.viewTitle("📡 Notification Center")).SetTitleAlign(tview.AlignCenter)
+
+	filters := tview.NewFlex().
+		AddItem(kinds, 32, 0, false).
+		AddItem(search, 0, 1, false)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(filters, 1, 0, false).
+		AddItem(table, 0, 1, true).
+		AddItem(tview.NewTextView().
+			SetText("Enter open | r read/unread | a all read | d delete | t type | / search | l delivery log | e rules | ESC back to Dashboard").
+			SetTextAlign(tview.AlignCenter), 1, 0, false)
+
+	v.Pages.AddAndSwitchToPage("notifications", flex, true)
+}
+
+// Delivery Log View
+func (v *Views) ShowDeliveriesView() {
+	if !v.State.IsLoggedIn() {
+		v.showMessage("Please login first!")
+		return
+	}
+
+	v.enterView()
+
+	table := tview.NewTable().SetBorders(true).SetSelectable(true, false)
+	v.liveUpdate = func() {
+		v.fillDeliveriesTable(table)
+	}
+	v.refreshLive()
+
+	table.Select(1, 0).SetFixed(1, 0).SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			v.ShowNotificationsView()
+		}
+	})
+
+	title := fmt.Sprintf("📤 Deliveries to %d sink(s)", v.Sinks.Len())
+	table.SetBorder(true).SetTitle(v.viewTitle(title)).SetTitleAlign(tview.AlignCenter)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(tview.NewTextView().
+			SetText("Newest first | ESC back to Notifications").
+			SetTextAlign(tview.AlignCenter), 1, 0, false)
+
+	v.Pages.AddAndSwitchToPage("deliveries", flex, true)
+}
+
+// fillDeliveriesTable renders recent sink deliveries, newest first
+func (v *Views) fillDeliveriesTable(table *tview.Table) {
+	table.Clear()
+
+	for col, title := range []string{"Time", "Sink", "Type", "Video", "Attempts", "Result"} {
+		table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(v.headerColor()).SetSelectable(false))
+	}
+
+	deliveries := v.Sinks.Deliveries()
+	if len(deliveries) == 0 {
+		text := "Nothing forwarded yet"
+		if v.Sinks.Len() == 0 {
+			text = "No sinks configured"
+		}
+		table.SetCell(1, 0, tview.NewTableCell(text))
+		table.SetCell(1, 1, tview.NewTableCell("Add webhooks or shell hooks under sinks: in the config file"))
+		return
+	}
+
+	slices.Reverse(deliveries)
+	for i, d := range deliveries {
+		row := i + 1
+		result := "✅ Delivered"
+		if d.Failed() {
+			result = "❌ " + d.Error
+		}
+		table.SetCell(row, 0, tview.NewTableCell(d.At.Format("Jan 2 15:04:05")))
+		table.SetCell(row, 1, tview.NewTableCell(tview.Escape(d.Sink)))
+		table.SetCell(row, 2, tview.NewTableCell(tview.Escape(string(d.Kind))))
+		table.SetCell(row, 3, tview.NewTableCell(tview.Escape(d.VideoID)))
+		table.SetCell(row, 4, tview.NewTableCell(fmt.Sprint(d.Attempts)))
+		table.SetCell(row, 5, tview.NewTableCell(tview.Escape(result)))
+	}
+}
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	proto "github.com/codek7-services/codek7-tui/pkg/pb"
+	"github.com/rivo/tview"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// isUnavailable reports whether an RPC failed because the server could not
+// be reached
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// saveCache stores the library after a successful fetch. Sessions without
+// credentials (demo mode) are not cached.
+func (v *Views) saveCache() {
+	if !v.State.HasCredentials() {
+		return
+	}
+	_, syncedAt := v.State.OfflineSince()
+	err := v.Cache.Save(&CachedLibrary{
+		SavedAt:       syncedAt,
+		User:          v.State.GetUser(),
+		Videos:        v.State.GetVideos(),
+		Notifications: v.State.GetNotifications(),
+	})
+	if err != nil {
+		slog.Warn("Saving library cache failed", "err", err)
+	}
+}
+
+// offlineBanner describes offline mode, or returns "" while online
+func (v *Views) offlineBanner() string {
+	offline, syncedAt := v.State.OfflineSince()
+	if !offline {
+		return ""
+	}
+	if syncedAt.IsZero() {
+		return "📴 Offline"
+	}
+	layout := "15:04"
+	if y, m, d := syncedAt.Date(); y != time.Now().Year() || m != time.Now().Month() || d != time.Now().Day() {
+		layout = "Jan 2 15:04"
+	}
+	return "📴 Offline, as of " + syncedAt.Format(layout)
+}
+
+// viewTitle adds the offline banner to a view title
+func (v *Views) viewTitle(title string) string {
+	if banner := v.offlineBanner(); banner != "" {
+		return title + " — " + banner
+	}
+	return title
+}
+
+// loadCache reads the cached library of the user who just logged in. A
+// cache saved under another user ID is ignored. It reads the disk, so call
+// it off the UI goroutine.
+func (v *Views) loadCache(user *proto.UserResponse) *CachedLibrary {
+	lib, err := v.Cache.Load(user.Username)
+	if err != nil {
+		slog.Warn("Loading library cache failed", "err", err)
+		return nil
+	}
+	if lib == nil || lib.User.GetId() != user.Id {
+		return nil
+	}
+	return lib
+}
+
+// applyCache shows a cached library until the fresh copy arrives, and
+// keeps showing it if the server drops before then
+func (v *Views) applyCache(lib *CachedLibrary) {
+	if lib == nil {
+		return
+	}
+	v.State.SetVideos(lib.Videos)
+	v.State.SetNotifications(lib.Notifications)
+	v.State.RebuildPipelines(lib.Notifications)
+	v.State.SetSynced(lib.SavedAt)
+}
+
+// handleServerState moves between online and offline mode as the gRPC
+// connection changes. Runs on the UI goroutine.
+func (v *Views) handleServerState(state connectivity.State) {
+	if !v.State.IsLoggedIn() {
+		return
+	}
+	switch state {
+	case connectivity.TransientFailure:
+		v.State.SetOffline(true)
+	case connectivity.Ready:
+		if v.State.IsReadOnly() {
+			v.endReadOnly()
+		} else if offline, _ := v.State.OfflineSince(); offline {
+			v.resync()
+		}
+	}
+}
+
+// offlinePrompt offers the cached library when the server is unreachable
+func offlinePrompt(lib *CachedLibrary) string {
+	return fmt.Sprintf("📴 Open %s's library as of %s?\nIt is read-only until you log in again.",
+		lib.User.Username, lib.SavedAt.Format("Jan 2 15:04"))
+}
+
+// offerReadOnly asks whether to open the cached library after a login
+// failed because the server is unreachable
+func (v *Views) offerReadOnly(lib *CachedLibrary) {
+	modal := tview.NewModal().
+		SetText("❌ The server is unreachable.\n\n" + offlinePrompt(lib)).
+		AddButtons([]string{"Open offline", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			v.Pages.RemovePage("confirm")
+			if buttonLabel == "Open offline" {
+				v.openReadOnly(lib)
+			}
+		})
+	v.Pages.AddPage("confirm", modal, false, true)
+}
+
+// openReadOnly shows a cached library without logging in. Nothing proves
+// who is at the keyboard, so the session stays offline: it is not cached,
+// opens no WebSocket and ends as soon as the server is back.
+func (v *Views) openReadOnly(lib *CachedLibrary) {
+	v.State.SetUser(lib.User)
+	v.State.SetReadOnly(true)
+	v.applyCache(lib)
+	v.State.SetOffline(true)
+	v.ShowDashboardView()
+}
+
+// endReadOnly closes a read-only session once the server is reachable, so
+// the user logs in before anything syncs
+func (v *Views) endReadOnly() {
+	username := v.State.GetUser().GetUsername()
+	v.State.Logout()
+	v.ShowLoginView()
+	v.showMessage(fmt.Sprintf("🛰️ The server is back.\n\nLog in as %s to sync your library.", username))
+}
+
+// resync refetches the library after the server came back
+func (v *Views) resync() {
+	go func() {
+		err := v.loadUserVideos(context.Background())
+		v.Draws.Queue(func() {
+			if err != nil {
+				slog.Warn("Resync failed", "err", err)
+			} else {
+				v.Outbox.Drain()
+			}
+			v.refreshLive()
+		})
+	}()
+}
@@ -0,0 +1,381 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UploadQueue holds uploads made while the server is unreachable and sends
+// them, oldest first, once it is back. Each user has their own outbox file.
+type UploadQueue struct {
+	views *Views
+	dir   string
+
+	mu       sync.Mutex
+	userID   string
+	box      *internal.Outbox
+	draining bool               // a drain is running
+	again    bool               // another drain was asked for while it ran
+	cancel   context.CancelFunc // stops the running drain
+}
+
+func NewUploadQueue(views *Views, dir string) *UploadQueue {
+	return &UploadQueue{views: views, dir: dir}
+}
+
+// open returns the logged-in user's outbox, or nil when there is none
+func (q *UploadQueue) open() (*internal.Outbox, error) {
+	user := q.views.State.GetUser()
+	if q.dir == "" || user == nil {
+		return nil, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.box != nil && q.userID == user.Id {
+		return q.box, nil
+	}
+	box, err := internal.OpenOutbox(filepath.Join(q.dir, url.PathEscape(user.Id)+".json"))
+	if err != nil {
+		return nil, err
+	}
+	q.userID, q.box = user.Id, box
+	return box, nil
+}
+
+// Len returns the number of queued uploads for the logged-in user
+func (q *UploadQueue) Len() int {
+	box, err := q.open()
+	if err != nil || box == nil {
+		return 0
+	}
+	return box.Len()
+}
+
+// Enqueue snapshots the file and appends it to the outbox. It reads the
+// whole file for the checksum, so call it off the UI goroutine.
+func (q *UploadQueue) Enqueue(path, title, description string) (int, error) {
+	box, err := q.open()
+	if err != nil {
+		return 0, err
+	}
+	if box == nil {
+		return 0, errors.New("the upload outbox is disabled")
+	}
+	user := q.views.State.GetUser()
+	item, err := internal.NewOutboxItem(path, title, description, user.Id)
+	if err != nil {
+		return 0, err
+	}
+	if err := box.Add(item); err != nil {
+		return 0, err
+	}
+	return box.Len(), nil
+}
+
+// Accept re-snapshots a file that changed after it was queued, so the
+// current contents are uploaded. Like Enqueue, it reads the whole file, so
+// call it off the UI goroutine.
+func (q *UploadQueue) Accept(item internal.OutboxItem) error {
+	box, err := q.open()
+	if err != nil || box == nil {
+		return err
+	}
+	fresh, err := internal.NewOutboxItem(item.Path, item.Title, item.Description, item.UserID)
+	if err != nil {
+		return err
+	}
+	fresh.ID, fresh.QueuedAt = item.ID, item.QueuedAt
+	return box.Update(fresh)
+}
+
+// Drop removes an item from the outbox
+func (q *UploadQueue) Drop(id string) error {
+	box, err := q.open()
+	if err != nil || box == nil {
+		return err
+	}
+	return box.Remove(id)
+}
+
+// Drain uploads queued items in order in the background. Items whose file
+// changed or that the server rejected are held back for the user to
+// decide; any other failure stops the drain so later items never overtake
+// earlier ones. A drain asked for while one runs starts again once it is
+// done, so items queued in the meantime are not left waiting.
+func (q *UploadQueue) Drain() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.draining {
+		q.again = true
+		return
+	}
+	q.draining = true
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	go func() {
+		for {
+			q.drain(ctx)
+
+			q.mu.Lock()
+			q.cancel()
+			again := q.again
+			q.again, q.draining = false, again
+			if again {
+				ctx, q.cancel = context.WithCancel(context.Background())
+			}
+			q.mu.Unlock()
+			if !again {
+				return
+			}
+		}
+	}()
+}
+
+// Cancel stops a running drain, for when the user logs out. The upload in
+// flight is abandoned and its item stays queued as it was.
+func (q *UploadQueue) Cancel() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.again = false
+	if q.cancel != nil {
+		q.cancel()
+	}
+}
+
+func (q *UploadQueue) drain(ctx context.Context) {
+	box, err := q.open()
+	if err != nil {
+		slog.Warn("Opening upload outbox failed", "err", err)
+		return
+	}
+	client := q.views.State.GetGRPCClient()
+	if box == nil || client == nil {
+		return
+	}
+
+	v := q.views
+	sent := 0
+	for _, item := range box.Items() {
+		if ctx.Err() != nil {
+			return
+		}
+		if item.Conflict {
+			continue // held back until the user accepts or drops it
+		}
+		if err := item.Verify(); err != nil {
+			item.LastError = err.Error()
+			item.Conflict = errors.Is(err, internal.ErrFileChanged) || errors.Is(err, os.ErrNotExist)
+			q.update(box, item)
+			if !item.Conflict {
+				break
+			}
+			continue
+		}
+
+		item.Attempts++
+		uploadCtx, cancel := rpcContext(ctx, v.Config.Timeouts.Upload)
+		err := internal.UploadVideo(uploadCtx, client, item.Path, item.Title, item.Description, item.UserID, v.Config.Transfer.ChunkSizeKB*1024)
+		cancel()
+		if ctx.Err() != nil {
+			return // cancelled, not failed
+		}
+		if err != nil {
+			item.LastError = err.Error()
+			// Retrying would be rejected again; the user has to act on it
+			item.Conflict = isRejected(err)
+			q.update(box, item)
+			if !item.Conflict {
+				break
+			}
+			continue
+		}
+
+		if err := box.Remove(item.ID); err != nil {
+			slog.Warn("Updating upload outbox failed", "err", err)
+		}
+		sent++
+		v.Draws.Queue(func() {
+			v.notify(newNotification(internal.KindUploadReceived, "",
+				fmt.Sprintf("Queued video '%s' uploaded successfully", item.Title)))
+		})
+	}
+
+	if sent > 0 {
+		v.loadUserVideos(ctx)
+		v.Draws.Queue(v.refreshLive)
+	}
+}
+
+// isRejected reports whether the server refused the upload itself, rather
+// than failing to take it
+func isRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.AlreadyExists, codes.PermissionDenied,
+		codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+func (q *UploadQueue) update(box *internal.Outbox, item internal.OutboxItem) {
+	if err := box.Update(item); err != nil {
+		slog.Warn("Updating upload outbox failed", "err", err)
+	}
+	q.views.Draws.Queue(q.views.refreshLive)
+}
+
+// queueUpload puts an upload in the outbox while the server is unreachable
+func (v *Views) queueUpload(filePath, title, description string) {
+	v.showMessage("📮 Server unreachable, queuing upload...")
+	go func() {
+		n, err := v.Outbox.Enqueue(filePath, title, description)
+		v.Draws.Queue(func() {
+			v.Pages.RemovePage("message")
+			if err != nil {
+				v.showError(fmt.Errorf("Queuing upload failed: %v", err))
+				return
+			}
+			v.showMessage(fmt.Sprintf("📮 Server unreachable.\n\n'%s' was queued in the outbox (%d waiting) "+
+				"and will upload automatically once the connection is back.", title, n))
+		})
+	}()
+}
+
+// Outbox View
+func (v *Views) ShowOutboxView() {
+	if !v.State.IsLoggedIn() {
+		v.showMessage("Please login first!")
+		return
+	}
+
+	v.enterView()
+
+	table := tview.NewTable().SetBorders(true).SetSelectable(true, false)
+	var items []internal.OutboxItem
+	v.liveUpdate = func() {
+		items = v.fillOutboxTable(table)
+	}
+	v.refreshLive()
+
+	selected := func() (internal.OutboxItem, bool) {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(items) {
+			return internal.OutboxItem{}, false
+		}
+		return items[row-1], true
+	}
+
+	table.Select(1, 0).SetFixed(1, 1).SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			v.ShowDashboardView()
+		}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'd':
+			if item, ok := selected(); ok {
+				v.confirmDrop(item)
+			}
+		case 'a':
+			if item, ok := selected(); ok && item.Conflict {
+				go func() {
+					err := v.Outbox.Accept(item)
+					v.Draws.Queue(func() {
+						if err != nil {
+							v.showError(err)
+						}
+						v.refreshLive()
+					})
+				}()
+			}
+		case 'r':
+			v.Outbox.Drain()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	table.SetBorder(true).SetTitle(v.viewTitle("📮 Upload Outbox")).SetTitleAlign(tview.AlignCenter)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(tview.NewTextView().
+			SetText("r retry now | a accept held item | d drop | ESC back to Dashboard").
+			SetTextAlign(tview.AlignCenter), 1, 0, false)
+
+	v.Pages.AddAndSwitchToPage("outbox", flex, true)
+}
+
+// fillOutboxTable renders the outbox and returns the items shown, in row order
+func (v *Views) fillOutboxTable(table *tview.Table) []internal.OutboxItem {
+	table.Clear()
+
+	for col, title := range []string{"#", "Title", "File", "Size", "Queued", "Status"} {
+		table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(v.headerColor()).SetSelectable(false))
+	}
+
+	box, err := v.Outbox.open()
+	if err != nil {
+		table.SetCell(1, 0, tview.NewTableCell(tview.Escape("❌ "+err.Error())))
+		return nil
+	}
+	var items []internal.OutboxItem
+	if box != nil {
+		items = box.Items()
+	}
+	if len(items) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("Outbox is empty"))
+		table.SetCell(1, 1, tview.NewTableCell("Uploads made offline wait here"))
+		return nil
+	}
+
+	for i, item := range items {
+		row := i + 1
+		status := "⏳ Waiting"
+		switch {
+		case item.Conflict:
+			status = "⚠️ " + item.LastError
+		case item.LastError != "":
+			status = fmt.Sprintf("❌ %s (attempt %d)", item.LastError, item.Attempts)
+		}
+		table.SetCell(row, 0, tview.NewTableCell(fmt.Sprintf("%d", row)))
+		table.SetCell(row, 1, tview.NewTableCell(tview.Escape(item.Title)))
+		table.SetCell(row, 2, tview.NewTableCell(tview.Escape(filepath.Base(item.Path))))
+		table.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf("%.2f MB", float64(item.Size)/(1024*1024))))
+		table.SetCell(row, 4, tview.NewTableCell(item.QueuedAt.Format("Jan 2 15:04")))
+		table.SetCell(row, 5, tview.NewTableCell(tview.Escape(status)))
+	}
+	return items
+}
+
+func (v *Views) confirmDrop(item internal.OutboxItem) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Drop queued upload '%s'?\n\nThe file itself is not touched.", item.Title)).
+		AddButtons([]string{"Drop", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			v.Pages.RemovePage("confirm")
+			if buttonLabel != "Drop" {
+				return
+			}
+			if err := v.Outbox.Drop(item.ID); err != nil {
+				v.showError(err)
+			}
+			v.refreshLive()
+		})
+	v.Pages.AddPage("confirm", modal, false, true)
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	proto "github.com/codek7-services/codek7-tui/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// bulkRefresh is how many changed videos make reloading the whole library
+// cheaper than fetching them one by one
+const bulkRefresh = 4
+
+// LibraryRefresher reloads videos that notifications say have changed.
+// Changes are collected until none arrive for the delay, then fetched in
+// one go, so a burst of events costs one reload.
+type LibraryRefresher struct {
+	views *Views
+	delay time.Duration
+
+	mu      sync.Mutex
+	pending map[string]bool // video ID -> removed
+	timer   *time.Timer
+}
+
+func NewLibraryRefresher(views *Views, delay time.Duration) *LibraryRefresher {
+	return &LibraryRefresher{views: views, delay: delay, pending: map[string]bool{}}
+}
+
+// Notice schedules a reload if the notification changes a video
+func (r *LibraryRefresher) Notice(n Notification) {
+	if r.delay <= 0 || n.Malformed() || n.Event.VideoID == "" {
+		return
+	}
+	switch n.Event.Kind {
+	case internal.KindVideoReady, internal.KindVideoRemoved, internal.KindProcessingFailed:
+	default:
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[n.Event.VideoID] = n.Event.Kind == internal.KindVideoRemoved
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(r.delay, r.flush)
+}
+
+// flush reloads the videos changed since the last flush
+func (r *LibraryRefresher) flush() {
+	r.mu.Lock()
+	changed := r.pending
+	r.pending = map[string]bool{}
+	r.timer = nil
+	r.mu.Unlock()
+
+	v := r.views
+	client := v.State.GetGRPCClient()
+	if len(changed) == 0 || client == nil || !v.State.IsLoggedIn() {
+		return
+	}
+	if offline, _ := v.State.OfflineSince(); offline {
+		return // the resync on reconnect reloads everything
+	}
+
+	if len(changed) >= bulkRefresh {
+		if err := v.loadUserVideos(context.Background()); err != nil {
+			slog.Warn("Refreshing videos failed", "err", err)
+		}
+		v.Draws.Queue(v.refreshLive)
+		return
+	}
+
+	for id, removed := range changed {
+		if removed {
+			v.State.RemoveVideo(id)
+			continue
+		}
+		ctx, cancel := rpcContext(context.Background(), v.Config.Timeouts.GetVideo)
+		video, err := client.GetVideoByID(ctx, &proto.GetVideoRequest{VideoId: id})
+		cancel()
+		switch {
+		case status.Code(err) == codes.NotFound:
+			v.State.RemoveVideo(id)
+		case err != nil:
+			slog.Warn("Refreshing video failed", "video", id, "err", err)
+		case video.UserId == "" || video.UserId == v.State.GetUser().GetId():
+			v.State.PutVideo(video)
+		}
+	}
+	v.saveCache()
+	v.Draws.Queue(v.refreshLive)
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// enterView cancels RPCs started by the previous view and returns the
+// context for the view being shown. Must run on the UI goroutine.
+func (v *Views) enterView() context.Context {
+	if v.cancelView != nil {
+		v.cancelView()
+	}
+	v.liveUpdate = nil
+	v.viewCtx, v.cancelView = context.WithCancel(context.Background())
+	v.refreshHeader()
+	return v.viewCtx
+}
+
+// currentView returns the context of the view on screen
+func (v *Views) currentView() context.Context {
+	if v.viewCtx == nil {
+		return v.enterView()
+	}
+	return v.viewCtx
+}
+
+// showMain switches to the main menu, leaving the current view
+func (v *Views) showMain() {
+	v.enterView()
+	v.liveUpdate = v.mainLive
+	v.refreshLive()
+	v.Pages.SwitchToPage("main")
+}
+
+// rpcContext bounds an RPC by its deadline and by the view that started it
+func rpcContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// isTimeout reports whether an RPC failed on its deadline
+func isTimeout(err error) bool {
+	return status.Code(err) == codes.DeadlineExceeded
+}
+
+// rpcError describes a failed RPC for the user. It returns nil when the
+// call was cancelled because the user left the view that started it.
+func rpcError(op string, timeout time.Duration, err error) error {
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.Canceled:
+		return nil
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("⏱️ %s timed out after %s; the server did not respond in time", op, timeout)
+	}
+	return fmt.Errorf("%s failed: %v", op, err)
+}
+
+// showRPCError reports a failed RPC unless it was cancelled
+func (v *Views) showRPCError(op string, timeout time.Duration, err error) {
+	if e := rpcError(op, timeout, err); e != nil {
+		v.showError(e)
+	}
+}
@@ -0,0 +1,298 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/codek7-services/codek7-tui/internal/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// loadRules compiles the notification rules from the config. Broken rules
+// are skipped; the config is validated at startup, so this only happens
+// when it was edited by hand while running.
+func (v *Views) loadRules() {
+	rules, quiet, err := v.Config.NotificationRules()
+	if err != nil {
+		slog.Warn("Invalid notification rules", "err", err)
+	}
+	v.rules, v.quiet = rules, quiet
+}
+
+// decide applies the notification rules. Malformed frames only follow the
+// quiet hours.
+func (v *Views) decide(n Notification) internal.Decision {
+	if n.Malformed() {
+		return internal.Decision{Quiet: v.quiet.Contains(time.Now())}
+	}
+	return internal.Decide(v.rules, v.quiet, n.Event, time.Now())
+}
+
+// saveRules applies changed rules and writes them to the config file
+func (v *Views) saveRules(rules []config.RuleConfig, quiet string) {
+	v.Config.Notifications.Rules = rules
+	v.Config.Notifications.QuietHours = quiet
+	v.loadRules()
+	if err := v.Config.SaveNotificationRules(); err != nil {
+		v.showError(fmt.Errorf("Rules are active but could not be saved: %v", err))
+	}
+}
+
+// Notification Rules View
+func (v *Views) ShowRulesView() {
+	if !v.State.IsLoggedIn() {
+		v.showMessage("Please login first!")
+		return
+	}
+
+	v.enterView()
+
+	table := tview.NewTable().SetBorders(true).SetSelectable(true, false)
+	quietText := tview.NewTextView().SetTextAlign(tview.AlignCenter)
+	v.liveUpdate = func() {
+		v.fillRulesTable(table)
+		quiet := "🌙 Quiet hours: off"
+		if v.quiet.String() != "" {
+			quiet = "🌙 Quiet hours: " + v.quiet.String() + " (only priority notifications interrupt)"
+			if v.quiet.Contains(time.Now()) {
+				quiet += " - now quiet"
+			}
+		}
+		quietText.SetText(quiet)
+	}
+	v.refreshLive()
+
+	rules := func() []config.RuleConfig {
+		return slices.Clone(v.Config.Notifications.Rules)
+	}
+	selected := func() int {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(v.Config.Notifications.Rules) {
+			return -1
+		}
+		return row - 1
+	}
+	move := func(delta int) {
+		i := selected()
+		j := i + delta
+		list := rules()
+		if i < 0 || j < 0 || j >= len(list) {
+			return
+		}
+		list[i], list[j] = list[j], list[i]
+		v.saveRules(list, v.Config.Notifications.QuietHours)
+		v.refreshLive()
+		table.Select(j+1, 0)
+	}
+
+	table.Select(1, 0).SetFixed(1, 0).SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			v.ShowNotificationsView()
+		}
+	})
+	table.SetSelectedFunc(func(row, _ int) {
+		if i := selected(); i >= 0 {
+			v.showRuleForm(i)
+		}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'n':
+			v.showRuleForm(-1)
+		case 'e':
+			if i := selected(); i >= 0 {
+				v.showRuleForm(i)
+			}
+		case 'd':
+			if i := selected(); i >= 0 {
+				v.saveRules(slices.Delete(rules(), i, i+1), v.Config.Notifications.QuietHours)
+				v.refreshLive()
+			}
+		case 'k':
+			move(-1)
+		case 'j':
+			move(1)
+		case 'q':
+			v.showQuietHoursForm()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	table.SetBorder(true).SetTitle("⚖️  Notification Rules - first match wins").SetTitleAlign(tview.AlignCenter)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(quietText, 1, 0, false).
+		AddItem(table, 0, 1, true).
+		AddItem(tview.NewTextView().
+			SetText("n new | Enter edit | d delete | k/j move up/down | q quiet hours | ESC back to Notifications").
+			SetTextAlign(tview.AlignCenter), 1, 0, false)
+
+	v.Pages.AddAndSwitchToPage("rules", flex, true)
+}
+
+// fillRulesTable renders the notification rules in match order
+func (v *Views) fillRulesTable(table *tview.Table) {
+	table.Clear()
+
+	for col, title := range []string{"#", "Name", "Action", "Types", "Message", "Video"} {
+		table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(v.headerColor()).SetSelectable(false))
+	}
+
+	rules := v.Config.Notifications.Rules
+	if len(rules) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(""))
+		table.SetCell(1, 1, tview.NewTableCell("No rules; press n to add one"))
+		return
+	}
+
+	for i, r := range rules {
+		row := i + 1
+		types := strings.Join(r.Types, ", ")
+		if types == "" {
+			types = "any"
+		}
+		table.SetCell(row, 0, tview.NewTableCell(fmt.Sprint(row)))
+		table.SetCell(row, 1, tview.NewTableCell(tview.Escape(r.Name)))
+		table.SetCell(row, 2, tview.NewTableCell(ruleActionLabel(r.Action)))
+		table.SetCell(row, 3, tview.NewTableCell(tview.Escape(types)))
+		table.SetCell(row, 4, tview.NewTableCell(tview.Escape(r.Message)))
+		table.SetCell(row, 5, tview.NewTableCell(tview.Escape(r.Video)))
+	}
+}
+
+func ruleActionLabel(action string) string {
+	switch internal.RuleAction(action) {
+	case internal.ActionMute:
+		return "🔇 mute"
+	case internal.ActionPriority:
+		return "🚨 priority"
+	case internal.ActionDismiss:
+		return "💨 dismiss"
+	}
+	return action
+}
+
+// showRuleForm edits rule i, or adds a rule when i is -1
+func (v *Views) showRuleForm(i int) {
+	rule := config.RuleConfig{Action: string(internal.ActionMute)}
+	if i >= 0 {
+		rule = v.Config.Notifications.Rules[i]
+	}
+	types := strings.Join(rule.Types, ", ")
+
+	actions := make([]string, len(internal.RuleActions))
+	current := 0
+	for n, action := range internal.RuleActions {
+		actions[n] = string(action)
+		if string(action) == rule.Action {
+			current = n
+		}
+	}
+
+	form := tview.NewForm()
+	form.AddInputField("Name", rule.Name, 40, nil, func(text string) {
+		rule.Name = text
+	}).
+		AddInputField("Types", types, 60, nil, func(text string) {
+			types = text
+		}).
+		AddInputField("Message regex", rule.Message, 60, nil, func(text string) {
+			rule.Message = text
+		}).
+		AddInputField("Video ID", rule.Video, 40, nil, func(text string) {
+			rule.Video = text
+		}).
+		AddDropDown("Action", actions, current, func(option string, _ int) {
+			rule.Action = option
+		}).
+		AddButton("💾 Save", func() {
+			rule.Types = nil
+			for _, kind := range strings.Split(types, ",") {
+				if kind = strings.TrimSpace(kind); kind != "" {
+					rule.Types = append(rule.Types, kind)
+				}
+			}
+			rule.Name = strings.TrimSpace(rule.Name)
+			if rule.Name == "" {
+				v.showMessage("Please give the rule a name")
+				return
+			}
+			if _, err := rule.Rule(); err != nil {
+				v.showError(fmt.Errorf("Invalid rule: %v", err))
+				return
+			}
+
+			rules := slices.Clone(v.Config.Notifications.Rules)
+			if i >= 0 {
+				rules[i] = rule
+			} else {
+				rules = append(rules, rule)
+			}
+			v.saveRules(rules, v.Config.Notifications.QuietHours)
+			v.ShowRulesView()
+		}).
+		AddButton("Cancel", v.ShowRulesView)
+	form.SetCancelFunc(v.ShowRulesView)
+
+	title := "➕ New Rule"
+	if i >= 0 {
+		title = "✏️  Edit Rule"
+	}
+	form.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignCenter)
+
+	helpText := tview.NewTextView().
+		SetText("• Types: comma separated, e.g. processing_progress, system (empty matches any)\n" +
+			"• Message: regular expression matched against the notification text\n" +
+			"• mute: never shown | priority: sticky toast and bell, even in quiet hours | dismiss: shown, then marked read").
+		SetBorder(true).
+		SetTitle("ℹ️ Help")
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(helpText, 5, 0, false).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(form, 80, 1, true).
+			AddItem(nil, 0, 1, false), 0, 1, true)
+
+	v.Pages.AddAndSwitchToPage("rule", flex, true)
+}
+
+func (v *Views) showQuietHoursForm() {
+	quiet := v.Config.Notifications.QuietHours
+
+	form := tview.NewForm()
+	form.AddInputField("Quiet hours", quiet, 20, nil, func(text string) {
+		quiet = strings.TrimSpace(text)
+	}).
+		AddButton("💾 Save", func() {
+			if _, err := internal.ParseQuietHours(quiet); err != nil {
+				v.showError(fmt.Errorf("Invalid quiet hours: %v", err))
+				return
+			}
+			v.saveRules(v.Config.Notifications.Rules, quiet)
+			v.ShowRulesView()
+		}).
+		AddButton("Cancel", v.ShowRulesView)
+	form.SetCancelFunc(v.ShowRulesView)
+	form.SetBorder(true).SetTitle("🌙 Quiet Hours - HH:MM-HH:MM, empty for none").SetTitleAlign(tview.AlignCenter)
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, 7, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	v.Pages.AddAndSwitchToPage("quiet", flex, true)
+}
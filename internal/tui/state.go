@@ -1,8 +1,16 @@
 package tui
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/codek7-services/codek7-tui/internal"
 	proto "github.com/codek7-services/codek7-tui/pkg/pb"
 )
 
@@ -13,15 +21,101 @@ type AppState struct {
 	CurrentUser   *proto.UserResponse
 	Token         string
 	Videos        []*proto.VideoMetadataResponse
+	RecentVideos  []*proto.VideoMetadataResponse // the server's latest uploads, apart from the library
 	Notifications []Notification
+	Pipelines     map[string]*internal.Pipeline // processing timelines by video ID
 	GRPCClient    proto.RepoServiceClient
+	Locked        bool
+
+	// Offline is set while the server is unreachable; the library shown is
+	// the one fetched at SyncedAt
+	Offline  bool
+	SyncedAt time.Time
+
+	// ReadOnly marks a session opened from the library cache without
+	// logging in; it never talks to the server as the user
+	ReadOnly bool
+
+	// Salted hash of the login password, used to unlock an idle session
+	credSalt []byte
+	credHash []byte
 }
 
+// Notification is an event as this client received it. Frames that could
+// not be decoded are kept too, with their raw text and the error.
 type Notification struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Message string `json:"message"`
-	Time    string `json:"time"`
+	Event    internal.Event `json:"event"`
+	Received time.Time      `json:"received"`
+	Raw      string         `json:"raw,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Read     bool           `json:"read,omitempty"`
+	Missed   bool           `json:"missed,omitempty"` // replayed after the client was away
+}
+
+// kindMalformed labels frames that could not be decoded
+const kindMalformed = "malformed"
+
+// localIDPrefix starts the IDs of events created by this client
+const localIDPrefix = "local-"
+
+// newNotification wraps an event created by this client
+func newNotification(kind internal.EventKind, videoID, message string) Notification {
+	now := time.Now()
+	return Notification{
+		Event: internal.Event{
+			Version:   internal.EventSchemaVersion,
+			ID:        fmt.Sprintf("%s%d", localIDPrefix, now.UnixNano()),
+			Kind:      kind,
+			VideoID:   videoID,
+			Timestamp: now,
+			Message:   message,
+		},
+		Received: now,
+	}
+}
+
+// Key identifies the notification: the server's event ID, or the time it
+// arrived for events without one
+func (n Notification) Key() string {
+	if n.Event.ID != "" {
+		return n.Event.ID
+	}
+	return n.Received.Format(time.RFC3339Nano)
+}
+
+// Local reports whether this client raised the notification rather than
+// the notifier
+func (n Notification) Local() bool {
+	return strings.HasPrefix(n.Event.ID, localIDPrefix)
+}
+
+// Malformed reports whether the frame could not be decoded
+func (n Notification) Malformed() bool {
+	return n.Error != ""
+}
+
+// Kind returns the event kind, or "malformed"
+func (n Notification) Kind() string {
+	if n.Malformed() {
+		return kindMalformed
+	}
+	return string(n.Event.Kind)
+}
+
+// Text describes the notification in one line
+func (n Notification) Text() string {
+	if n.Malformed() {
+		return fmt.Sprintf("%s: %s", n.Error, n.Raw)
+	}
+	return n.Event.Summary()
+}
+
+// Time is the server timestamp, or the time of receipt without one
+func (n Notification) Time() time.Time {
+	if !n.Event.Timestamp.IsZero() {
+		return n.Event.Timestamp
+	}
+	return n.Received
 }
 
 func NewAppState() *AppState {
@@ -29,14 +123,33 @@ func NewAppState() *AppState {
 		LoggedIn:      false,
 		Videos:        make([]*proto.VideoMetadataResponse, 0),
 		Notifications: make([]Notification, 0),
+		Pipelines:     make(map[string]*internal.Pipeline),
 	}
 }
 
+// SetUser logs the user in. Switching to another user drops what belonged
+// to the previous one, so it is never shown, cached or recorded under the
+// new account.
 func (s *AppState) SetUser(user *proto.UserResponse) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.CurrentUser != nil && user != nil && s.CurrentUser.Id != user.Id {
+		s.resetUserData()
+	}
 	s.CurrentUser = user
 	s.LoggedIn = true
+	s.ReadOnly = false
+}
+
+// resetUserData clears the library, notifications and sync state; called
+// with s.mu held
+func (s *AppState) resetUserData() {
+	s.Offline = false
+	s.SyncedAt = time.Time{}
+	s.Pipelines = make(map[string]*internal.Pipeline)
+	s.Videos = make([]*proto.VideoMetadataResponse, 0)
+	s.RecentVideos = nil
+	s.Notifications = make([]Notification, 0)
 }
 
 func (s *AppState) GetUser() *proto.UserResponse {
@@ -57,26 +170,153 @@ func (s *AppState) SetVideos(videos []*proto.VideoMetadataResponse) {
 	s.Videos = videos
 }
 
+// PutVideo replaces the video with the same ID, or appends it
+func (s *AppState) PutVideo(video *proto.VideoMetadataResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	videos := slices.Clone(s.Videos)
+	if i := slices.IndexFunc(videos, func(v *proto.VideoMetadataResponse) bool { return v.Id == video.Id }); i >= 0 {
+		videos[i] = video
+	} else {
+		videos = append(videos, video)
+	}
+	s.Videos = videos
+}
+
+func (s *AppState) RemoveVideo(videoID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := func(v *proto.VideoMetadataResponse) bool { return v.Id == videoID }
+	s.Videos = slices.DeleteFunc(slices.Clone(s.Videos), removed)
+	s.RecentVideos = slices.DeleteFunc(slices.Clone(s.RecentVideos), removed)
+}
+
 func (s *AppState) GetVideos() []*proto.VideoMetadataResponse {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.Videos
 }
 
+func (s *AppState) SetRecentVideos(videos []*proto.VideoMetadataResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RecentVideos = videos
+}
+
+// GetRecentVideos returns the last recent videos fetched; nil before the
+// first fetch
+func (s *AppState) GetRecentVideos() []*proto.VideoMetadataResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.RecentVideos
+}
+
 func (s *AppState) AddNotification(notif Notification) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.Notifications = append(s.Notifications, notif)
-	// Keep only last 50 notifications
-	if len(s.Notifications) > 50 {
+	s.trackPipeline(notif)
+	// Older ones live in the notification history
+	if len(s.Notifications) > maxNotifications {
 		s.Notifications = s.Notifications[1:]
 	}
 }
 
+// UnreadCount returns how many of the recent notifications are unread
+func (s *AppState) UnreadCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	unread := 0
+	for _, n := range s.Notifications {
+		if !n.Read {
+			unread++
+		}
+	}
+	return unread
+}
+
+// SetRead marks the notifications with the given keys read or unread
+func (s *AppState) SetRead(read bool, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.Notifications {
+		if slices.Contains(keys, s.Notifications[i].Key()) {
+			s.Notifications[i].Read = read
+		}
+	}
+}
+
+func (s *AppState) MarkAllRead() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.Notifications {
+		s.Notifications[i].Read = true
+	}
+}
+
+func (s *AppState) RemoveNotification(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Notifications = slices.DeleteFunc(slices.Clone(s.Notifications), func(n Notification) bool {
+		return n.Key() == key
+	})
+}
+
+// trackPipeline moves the timeline of the notification's video along
+func (s *AppState) trackPipeline(n Notification) {
+	if n.Malformed() || n.Event.VideoID == "" {
+		return
+	}
+	if _, ok := internal.StageFor(n.Event.Kind); !ok {
+		return
+	}
+	p := s.Pipelines[n.Event.VideoID]
+	if p == nil {
+		p = &internal.Pipeline{VideoID: n.Event.VideoID}
+		s.Pipelines[n.Event.VideoID] = p
+	}
+	p.Apply(n.Event, n.Time())
+}
+
+// RebuildPipelines replaces the processing timelines with ones replayed
+// from the given notifications
+func (s *AppState) RebuildPipelines(notifications []Notification) {
+	events := make([]internal.TimedEvent, 0, len(notifications))
+	for _, n := range notifications {
+		if !n.Malformed() {
+			events = append(events, internal.TimedEvent{Event: n.Event, At: n.Time()})
+		}
+	}
+	pipelines := internal.BuildPipelines(events)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Pipelines = pipelines
+}
+
+// Pipeline returns a copy of the video's processing timeline, or nil
+func (s *AppState) Pipeline(videoID string) *internal.Pipeline {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p := s.Pipelines[videoID]
+	if p == nil {
+		return nil
+	}
+	return &internal.Pipeline{VideoID: p.VideoID, Steps: slices.Clone(p.Steps)}
+}
+
+func (s *AppState) SetNotifications(notifications []Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Notifications = slices.Clone(notifications)
+}
+
+// GetNotifications returns a copy of the recent notifications; change them
+// through the state's methods
 func (s *AppState) GetNotifications() []Notification {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.Notifications
+	return slices.Clone(s.Notifications)
 }
 
 func (s *AppState) SetGRPCClient(client proto.RepoServiceClient) {
@@ -91,11 +331,95 @@ func (s *AppState) GetGRPCClient() proto.RepoServiceClient {
 	return s.GRPCClient
 }
 
+func (s *AppState) SetLocked(locked bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Locked = locked
+}
+
+func (s *AppState) IsLocked() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Locked
+}
+
+// SetSynced records a successful fetch and leaves offline mode
+func (s *AppState) SetSynced(at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.SyncedAt = at
+	s.Offline = false
+}
+
+func (s *AppState) SetReadOnly(readOnly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ReadOnly = readOnly
+}
+
+func (s *AppState) IsReadOnly() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ReadOnly
+}
+
+func (s *AppState) SetOffline(offline bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Offline = offline
+}
+
+// OfflineSince reports whether the app is offline and when the library
+// shown was last synced
+func (s *AppState) OfflineSince() (bool, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Offline, s.SyncedAt
+}
+
+// SetCredentials remembers a salted hash of the password for unlocking
+func (s *AppState) SetCredentials(password string) {
+	salt := make([]byte, 16)
+	_, _ = rand.Read(salt)
+	sum := sha256.Sum256(append(salt, password...))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.credSalt = salt
+	s.credHash = sum[:]
+}
+
+func (s *AppState) HasCredentials() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.credHash != nil
+}
+
+// CheckPassword reports whether password matches the stored credentials.
+// Sessions without credentials (demo mode) accept any password.
+func (s *AppState) CheckPassword(password string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.credHash == nil {
+		return true
+	}
+	return matchPassword(s.credSalt, s.credHash, password)
+}
+
+func matchPassword(salt, hash []byte, password string) bool {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), password...))
+	return subtle.ConstantTimeCompare(sum[:], hash) == 1
+}
+
 func (s *AppState) Logout() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.LoggedIn = false
+	s.Locked = false
+	s.ReadOnly = false
 	s.CurrentUser = nil
 	s.Token = ""
-	s.Videos = make([]*proto.VideoMetadataResponse, 0)
+	s.credSalt = nil
+	s.credHash = nil
+	s.resetUserData()
 }
@@ -0,0 +1,20 @@
+package tui
+
+import (
+	"github.com/codek7-services/codek7-tui/internal/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// applyTheme sets the global tview styles from the configured theme
+func applyTheme(theme config.ThemeConfig) {
+	tview.Styles.PrimitiveBackgroundColor = tcell.GetColor(theme.Background)
+	tview.Styles.PrimaryTextColor = tcell.GetColor(theme.Text)
+	tview.Styles.BorderColor = tcell.GetColor(theme.Border)
+	tview.Styles.TitleColor = tcell.GetColor(theme.Title)
+}
+
+// headerColor is the color used for table headers
+func (v *Views) headerColor() tcell.Color {
+	return tcell.GetColor(v.Config.Theme.Header)
+}
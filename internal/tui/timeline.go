@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/rivo/tview"
+)
+
+// stageIcon returns the emoji shown next to a pipeline stage
+func stageIcon(stage internal.Stage) string {
+	switch stage {
+	case internal.StageReceived:
+		return "📥"
+	case internal.StageQueued:
+		return "⏳"
+	case internal.StageProcessing:
+		return "⚙️"
+	case internal.StageReady:
+		return "✅"
+	case internal.StageFailed:
+		return "❌"
+	}
+	return "❔"
+}
+
+// videoStatus describes where a video is in processing, for the videos table
+func (v *Views) videoStatus(videoID string) string {
+	p := v.State.Pipeline(videoID)
+	step, ok := p.Current()
+	if !ok {
+		return "—"
+	}
+	return stageIcon(step.Stage) + " " + p.Status()
+}
+
+// videoTimeline renders a video's processing timeline for the detail page
+func (v *Views) videoTimeline(videoID string) string {
+	p := v.State.Pipeline(videoID)
+	if p == nil {
+		return "No processing events for this video yet\n"
+	}
+
+	now := time.Now()
+	var b strings.Builder
+	for _, step := range p.Steps {
+		fmt.Fprintf(&b, "%s %-11s %s", stageIcon(step.Stage), step.Stage,
+			step.Started.Local().Format("Jan 2 15:04:05"))
+		if !step.Stage.Done() {
+			d := step.Duration(now).Round(time.Second)
+			if step.Ended.IsZero() {
+				fmt.Fprintf(&b, "  %s so far", d)
+			} else {
+				fmt.Fprintf(&b, "  took %s", d)
+			}
+		}
+		switch {
+		case step.Stage == internal.StageProcessing && step.Percent > 0:
+			fmt.Fprintf(&b, "  %.0f%%", step.Percent)
+			if step.Detail != "" {
+				fmt.Fprintf(&b, " (%s)", tview.Escape(step.Detail))
+			}
+		case step.Detail != "":
+			fmt.Fprintf(&b, "  %s", tview.Escape(step.Detail))
+		}
+		b.WriteString("\n")
+	}
+
+	if first, last := p.Steps[0], p.Steps[len(p.Steps)-1]; last.Stage.Done() && len(p.Steps) > 1 {
+		fmt.Fprintf(&b, "\nTotal: %s\n", last.Started.Sub(first.Started).Round(time.Second))
+	}
+	return b.String()
+}
@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	toastWidth    = 44
+	toastMaxLines = 3
+	toastFade     = time.Second // drawn dimmed for this long before expiring
+)
+
+// Toasts draws short-lived messages stacked in the bottom-right corner, on
+// top of the primitive it wraps. Input and focus go to the wrapped
+// primitive, so toasts never steal keys. The idle lock swaps the whole
+// root, which hides toasts while the session is locked.
+type Toasts struct {
+	tview.Primitive
+
+	draws *DrawQueue
+	ttl   time.Duration
+	limit int
+
+	mu    sync.Mutex
+	items []toast
+}
+
+type toast struct {
+	text    string
+	color   tcell.Color
+	expires time.Time
+	sticky  bool   // stays until dismissed
+	hint    string // border title, e.g. how to dismiss
+}
+
+func NewToasts(draws *DrawQueue, root tview.Primitive, ttl time.Duration, limit int) *Toasts {
+	return &Toasts{
+		Primitive: root,
+		draws:     draws,
+		ttl:       ttl,
+		limit:     limit,
+	}
+}
+
+// Show adds a toast; the oldest is dropped when too many are stacked.
+// Safe to call from any goroutine.
+func (t *Toasts) Show(text string, color tcell.Color) {
+	if t.ttl <= 0 {
+		return
+	}
+	t.add(toast{text: text, color: color, expires: time.Now().Add(t.ttl)})
+
+	// Redraw when the toast starts to fade and when it is gone
+	redraw := func() { t.draws.Queue(func() {}) }
+	if t.ttl > toastFade {
+		time.AfterFunc(t.ttl-toastFade, redraw)
+	}
+	time.AfterFunc(t.ttl, redraw)
+}
+
+// ShowSticky adds a toast that stays until DismissSticky, even when
+// toasts are otherwise turned off
+func (t *Toasts) ShowSticky(text, hint string, color tcell.Color) {
+	t.add(toast{text: text, color: color, sticky: true, hint: hint})
+}
+
+// add appends a toast, dropping the oldest ones over the limit; sticky
+// toasts go last
+func (t *Toasts) add(item toast) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.items = append(t.items, item)
+	for len(t.items) > t.limit {
+		drop := 0
+		for i, old := range t.items {
+			if !old.sticky {
+				drop = i
+				break
+			}
+		}
+		t.items = append(t.items[:drop], t.items[drop+1:]...)
+	}
+}
+
+// DismissSticky removes sticky toasts and reports whether there were any
+func (t *Toasts) DismissSticky() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	kept := t.items[:0]
+	for _, item := range t.items {
+		if !item.sticky {
+			kept = append(kept, item)
+		}
+	}
+	dismissed := len(kept) < len(t.items)
+	t.items = kept
+	return dismissed
+}
+
+// visible drops expired toasts and returns the rest, oldest first
+func (t *Toasts) visible() []toast {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	live := t.items[:0]
+	for _, item := range t.items {
+		if item.sticky || item.expires.After(now) {
+			live = append(live, item)
+		}
+	}
+	t.items = live
+	return append([]toast(nil), live...)
+}
+
+func (t *Toasts) Draw(screen tcell.Screen) {
+	t.Primitive.Draw(screen)
+
+	items := t.visible()
+	if len(items) == 0 {
+		return
+	}
+
+	x, y, width, height := t.GetRect()
+	w := toastWidth
+	if w > width-2 {
+		w = width - 2
+	}
+	if w < 10 {
+		return
+	}
+
+	// Newest at the bottom, stacking upwards
+	bottom := y + height - 1
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		lines := tview.WordWrap(item.text, w-2)
+		if len(lines) > toastMaxLines {
+			lines = lines[:toastMaxLines]
+		}
+		h := len(lines) + 2
+		top := bottom - h + 1
+		if top < y+1 {
+			break
+		}
+
+		color := item.color
+		if !item.sticky && time.Until(item.expires) < toastFade {
+			color = tcell.ColorGray
+		}
+		box := tview.NewTextView().SetText(item.text).SetWrap(true)
+		box.SetBorder(true).SetBorderColor(color)
+		if item.hint != "" {
+			box.SetTitle(" " + item.hint + " ").SetTitleColor(color)
+		}
+		box.SetRect(x+width-w-1, top, w, h)
+		box.Draw(screen)
+
+		bottom = top - 1
+	}
+}
+
+// toastText renders a notification for a toast
+func toastText(n Notification) string {
+	return kindIcon(n.Kind()) + " " + n.Text()
+}
+
+// toastColor picks the border color of a notification's toast
+func toastColor(n Notification) tcell.Color {
+	switch n.Kind() {
+	case string(internal.KindVideoReady):
+		return tcell.ColorGreen
+	case string(internal.KindProcessingFailed), kindMalformed:
+		return tcell.ColorRed
+	}
+	return tview.Styles.BorderColor
+}
+
+// kindIcon returns the emoji shown next to a notification kind
+func kindIcon(kind string) string {
+	switch internal.EventKind(kind) {
+	case internal.KindUploadReceived:
+		return "📥"
+	case internal.KindProcessingQueued:
+		return "⏳"
+	case internal.KindProcessingStarted, internal.KindProcessingProgress:
+		return "⚙️"
+	case internal.KindVideoReady:
+		return "✅"
+	case internal.KindProcessingFailed:
+		return "❌"
+	case internal.KindVideoRemoved:
+		return "🗑️"
+	case internal.KindSystem:
+		return "📢"
+	}
+	if kind == kindMalformed {
+		return "⚠️"
+	}
+	return "🔔"
+}
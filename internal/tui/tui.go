@@ -1,46 +1,87 @@
 package tui
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/codek7-services/codek7-tui/internal/config"
 	proto "github.com/codek7-services/codek7-tui/pkg/pb"
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/backoff"
 )
 
 type App struct {
 	App       *tview.Application
 	Pages     *tview.Pages
+	Config    *config.Config
 	State     *AppState
 	Views     *Views
 	WSManager *WebSocketManager
+	GRPC      *GRPCMonitor
+	Locker    *IdleLocker
+	Toasts    *Toasts
+	Alerts    *TerminalAlerts
+	Sinks     *internal.Dispatcher
 }
 
-func NewApp() *App {
+func NewApp(cfg *config.Config) *App {
+	applyTheme(cfg.Theme)
+
 	app := tview.NewApplication()
 	pages := tview.NewPages()
 	state := NewAppState()
 
 	// Initialize gRPC client
-	grpcAddr := os.Getenv("GRPC_ADDR")
-	if grpcAddr == "" {
-		grpcAddr = "localhost:50051" // default
+	creds, err := cfg.GRPCTLSOptions().TransportCredentials()
+	if err != nil {
+		slog.Warn("Invalid gRPC TLS settings", "err", err)
 	}
 
-	conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	var conn *grpc.ClientConn
+	if err == nil {
+		proxy := cfg.ProxyOptions()
+		target := cfg.GRPC.Addr
+		// Through a proxy, the proxy resolves the host name, not us
+		if p, _ := proxy.ProxyFor(target); p != nil && !strings.Contains(target, "://") {
+			target = "passthrough:///" + target
+		}
+		conn, err = grpc.NewClient(target,
+			grpc.WithTransportCredentials(creds),
+			grpc.WithContextDialer(proxy.Dial),
+			// Our retry policy is authoritative; ignore configs from the resolver
+			grpc.WithDisableServiceConfig(),
+			grpc.WithDefaultServiceConfig(cfg.GRPCRetryPolicy().ServiceConfig()),
+			grpc.WithConnectParams(grpc.ConnectParams{
+				Backoff:           backoff.DefaultConfig,
+				MinConnectTimeout: cfg.Timeouts.Connect,
+			}))
+	}
 	if err != nil {
-		log.Printf("Failed to connect to gRPC server: %v", err)
+		slog.Warn("Failed to connect to gRPC server", "err", err)
 	} else {
 		client := proto.NewRepoServiceClient(conn)
 		state.SetGRPCClient(client)
 	}
 
-	views := NewViews(app, pages, state)
+	views := NewViews(app, pages, state, cfg)
+
+	// Follow the gRPC connection state
+	monitor := NewGRPCMonitor(conn, views.Draws)
+	views.SetGRPCMonitor(monitor)
+	monitor.Start()
 
-	// Initialize WebSocket manager
-	wsManager := NewWebSocketManager(state, app)
+	// Initialize WebSocket manager, catching up on missed events if enabled
+	var cursors *internal.CursorStore
+	if cfg.Notifier.CatchUp {
+		cursors = &internal.CursorStore{Dir: cfg.Notifier.CursorDir}
+	}
+	wsManager := NewWebSocketManager(state, views.Draws, cfg.NotifierOptions(), cursors)
 	views.SetWebSocketManager(wsManager)
 
 	// Create main menu
@@ -55,6 +96,7 @@ func NewApp() *App {
 			}
 		}).
 		AddItem("🎭 Demo Mode", "Try the app with demo data", 'm', views.EnableDemoMode).
+		AddItem("🛰️  Reconnect Server", "Retry the gRPC connection now", shortcut(cfg.Keys.Reconnect), views.reconnectServer).
 		AddItem("❌ Quit", "Exit the application", 'q', func() {
 			app.Stop()
 		})
@@ -68,27 +110,86 @@ func NewApp() *App {
 		SetBorder(true).
 		SetTitle("Welcome")
 
+	serverStatus := tview.NewTextView().SetTextAlign(tview.AlignCenter)
+	views.mainLive = func() {
+		serverStatus.SetText("🛰️ Server " + cfg.GRPC.Addr + ": " + views.grpcStatus())
+	}
+	views.mainLive()
+
 	mainFlex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(welcomeText, 9, 0, false).
+		AddItem(serverStatus, 1, 0, false).
 		AddItem(mainMenu, 0, 1, true)
 
 	pages.AddPage("main", mainFlex, true, true)
+	views.liveUpdate = views.mainLive
+
+	// Status line above every page, toasts on top of everything
+	header := tview.NewTextView().SetDynamicColors(true)
+	views.header = header
+	frame := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(header, 1, 0, false).
+		AddItem(pages, 0, 1, true)
+	toasts := NewToasts(views.Draws, frame, cfg.Notifications.ToastTimeout, cfg.Notifications.MaxToasts)
+	views.Toasts = toasts
+	views.refreshHeader()
+
+	// Bell and desktop notifications for when the TUI is in the background
+	alerts := NewTerminalAlerts(internal.DesktopMode(cfg.Notifications.Desktop), os.Getenv("TMUX") != "",
+		cfg.Notifications.DesktopTypes, os.Getenv, state.IsLocked)
+	views.Alerts = alerts
+	app.SetAfterDrawFunc(alerts.Draw)
+
+	// Mirror notifications into webhooks and shell hooks
+	var sinks []internal.Sink
+	for _, opts := range cfg.SinkOptions() {
+		sink, err := internal.NewSink(opts)
+		if err != nil {
+			slog.Warn("Skipping notification sink", "err", err)
+			continue
+		}
+		sinks = append(sinks, sink)
+	}
+	dispatcher := internal.NewDispatcher(sinks, cfg.Notifications.DeliveryLog)
+	dispatcher.SetDeliveryHandler(func(d internal.Delivery) {
+		if d.Failed() {
+			toasts.Show(fmt.Sprintf("📤 Sink %s failed: %s", d.Sink, d.Error), tcell.ColorRed)
+		}
+		views.Draws.Queue(views.refreshLive)
+	})
+	views.Sinks = dispatcher
 
 	tuiApp := &App{
 		App:       app,
 		Pages:     pages,
+		Config:    cfg,
 		State:     state,
 		Views:     views,
 		WSManager: wsManager,
+		GRPC:      monitor,
+		Toasts:    toasts,
+		Alerts:    alerts,
+		Sinks:     dispatcher,
 	}
 
 	// Set the app root
-	app.SetRoot(pages, true)
+	app.SetRoot(toasts, true)
+
+	// Lock the session after a period without input
+	tuiApp.Locker = NewIdleLocker(views, toasts, cfg.Session.IdleLock)
+	tuiApp.Locker.Start()
+
+	if cfg.GRPC.Preflight {
+		views.ShowPreflightView()
+	}
 
 	return tuiApp
 }
 
 func (a *App) Run() error {
+	defer a.Sinks.Close(5 * time.Second) // let queued deliveries finish
+	defer a.Views.Draws.Stop()           // deliveries and timers finishing later must not block
 	return a.App.Run()
 }
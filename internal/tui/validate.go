@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+const (
+	minUsernameLen = 3
+	maxUsernameLen = 32
+	minPasswordLen = 8
+)
+
+// Field keys used for per-field registration errors
+const (
+	fieldUsername = "username"
+	fieldEmail    = "email"
+	fieldPassword = "password"
+	fieldConfirm  = "confirm"
+)
+
+// validateUsername checks the username charset and length rules
+func validateUsername(username string) error {
+	if username == "" {
+		return fmt.Errorf("username is required")
+	}
+	if len(username) < minUsernameLen || len(username) > maxUsernameLen {
+		return fmt.Errorf("must be %d-%d characters", minUsernameLen, maxUsernameLen)
+	}
+	for i, r := range username {
+		switch {
+		case r > unicode.MaxASCII:
+			return fmt.Errorf("only ASCII letters, digits, '.', '_' and '-' allowed")
+		case i == 0 && !unicode.IsLetter(r):
+			return fmt.Errorf("must start with a letter")
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.', r == '_', r == '-':
+		default:
+			return fmt.Errorf("only letters, digits, '.', '_' and '-' allowed")
+		}
+	}
+	return nil
+}
+
+// validateEmail performs basic RFC 5322 address validation
+func validateEmail(email string) error {
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return fmt.Errorf("not a valid email address")
+	}
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return fmt.Errorf("email domain looks incomplete")
+	}
+	return nil
+}
+
+// validatePassword enforces the minimum password requirements
+func validatePassword(password string) error {
+	if password == "" {
+		return fmt.Errorf("password is required")
+	}
+	if len(password) < minPasswordLen {
+		return fmt.Errorf("must be at least %d characters", minPasswordLen)
+	}
+	if score, _ := passwordStrength(password); score < 2 {
+		return fmt.Errorf("too weak, mix letters, digits and symbols")
+	}
+	return nil
+}
+
+// validateRegistration validates every registration field and returns the
+// errors keyed by field; an empty map means the input is acceptable
+func validateRegistration(username, email, password, confirm string) map[string]string {
+	errs := make(map[string]string)
+	if err := validateUsername(username); err != nil {
+		errs[fieldUsername] = err.Error()
+	}
+	if err := validateEmail(email); err != nil {
+		errs[fieldEmail] = err.Error()
+	}
+	if err := validatePassword(password); err != nil {
+		errs[fieldPassword] = err.Error()
+	}
+	if confirm == "" {
+		errs[fieldConfirm] = "please confirm your password"
+	} else if confirm != password {
+		errs[fieldConfirm] = "passwords do not match"
+	}
+	return errs
+}
+
+// registrationErrors lists the registration errors in form order
+func registrationErrors(errs map[string]string) string {
+	var b strings.Builder
+	b.WriteString("Please correct the following:\n")
+	for _, field := range []struct{ key, label string }{
+		{fieldUsername, "Username"},
+		{fieldEmail, "Email"},
+		{fieldPassword, "Password"},
+		{fieldConfirm, "Confirm Password"},
+	} {
+		if msg, ok := errs[field.key]; ok {
+			fmt.Fprintf(&b, "\n• %s: %s", field.label, msg)
+		}
+	}
+	return b.String()
+}
+
+// passwordStrength scores a password from 0 (empty) to 4 (strong)
+func passwordStrength(password string) (int, string) {
+	if password == "" {
+		return 0, "Empty"
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+
+	score := 1
+	if len(password) >= minPasswordLen && classes >= 2 {
+		score = 2
+	}
+	if len(password) >= 10 && classes >= 3 {
+		score = 3
+	}
+	if len(password) >= 14 && classes == 4 {
+		score = 4
+	}
+
+	labels := []string{"Empty", "Weak", "Fair", "Good", "Strong"}
+	return score, labels[score]
+}
+
+// strengthMeter renders the password strength as a colored bar
+func strengthMeter(password string) string {
+	score, label := passwordStrength(password)
+	colors := []string{"gray", "red", "yellow", "green", "green"}
+	bar := strings.Repeat("█", score*3) + strings.Repeat("░", (4-score)*3)
+	return fmt.Sprintf("[%s]%s %s[-]", colors[score], bar, label)
+}
+
+// fieldError formats an inline form error message
+func fieldError(msg string) string {
+	if msg == "" {
+		return ""
+	}
+	return "[red]⚠ " + msg + "[-]"
+}
@@ -3,8 +3,11 @@ package tui
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/codek7-services/codek7-tui/internal"
+	"github.com/codek7-services/codek7-tui/internal/config"
 	proto "github.com/codek7-services/codek7-tui/pkg/pb"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -12,25 +15,196 @@ import (
 
 type Views struct {
 	App       *tview.Application
+	Draws     *DrawQueue
 	Pages     *tview.Pages
 	State     *AppState
+	Config    *config.Config
 	WSManager *WebSocketManager
+	GRPC      *GRPCMonitor
+	Cache     *LibraryCache
+	Outbox    *UploadQueue
+	Toasts    *Toasts
+	Alerts    *TerminalAlerts
+	History   *NotificationHistory
+	Refresher *LibraryRefresher
+	Sinks     *internal.Dispatcher
+
+	// Context of the view on screen; cancelled when another view is shown
+	viewCtx    context.Context
+	cancelView context.CancelFunc
+	// Redraws live parts of the view on screen, if it has any
+	liveUpdate func()
+	// Redraws the main menu status line
+	mainLive func()
+	// Status line shown above every page
+	header *tview.TextView
+	// Compiled notification rules and quiet hours
+	rules []internal.Rule
+	quiet internal.QuietHours
+	// Notifications replayed since the last catch-up summary
+	missed      int
+	missedTimer *time.Timer
 }
 
-func NewViews(app *tview.Application, pages *tview.Pages, state *AppState) *Views {
-	return &Views{
-		App:   app,
-		Pages: pages,
-		State: state,
+func NewViews(app *tview.Application, pages *tview.Pages, state *AppState, cfg *config.Config) *Views {
+	v := &Views{
+		App:    app,
+		Draws:  NewDrawQueue(app),
+		Pages:  pages,
+		State:  state,
+		Config: cfg,
+		Cache:  NewLibraryCache(cfg.Cache.Dir),
+		History: NewNotificationHistory(cfg.Notifications.HistoryDir,
+			cfg.Notifications.HistoryRetention, cfg.Notifications.HistoryMax),
 	}
+	v.Outbox = NewUploadQueue(v, cfg.Transfer.OutboxDir)
+	v.Refresher = NewLibraryRefresher(v, cfg.Notifications.RefreshDelay)
+	v.loadRules()
+	return v
 }
 
 func (v *Views) SetWebSocketManager(wsm *WebSocketManager) {
 	v.WSManager = wsm
+	wsm.SetStateHandler(func(internal.ConnStatus) {
+		v.refreshLive()
+	})
+	wsm.SetMessageHandler(v.onMessage)
+}
+
+// onMessage stores and surfaces a notification from the notifier
+func (v *Views) onMessage(n Notification) {
+	n, decision := v.admit(n)
+	if !n.Malformed() {
+		v.Sinks.Dispatch(n.Event)
+	}
+	v.surface(n, decision)
+}
+
+// notify stores and surfaces a notification raised by this client.
+// Must run on the UI goroutine.
+func (v *Views) notify(n Notification) {
+	n, decision := v.admit(n)
+	v.surface(n, decision)
+}
+
+// admit applies the notification rules and keeps the notification
+func (v *Views) admit(n Notification) (Notification, internal.Decision) {
+	decision := v.decide(n)
+	if decision.Action == internal.ActionMute || decision.Action == internal.ActionDismiss {
+		n.Read = true
+	}
+	v.State.AddNotification(n)
+	return n, decision
+}
+
+// surface records a notification that just arrived and shows it as the
+// rules say
+func (v *Views) surface(n Notification, decision internal.Decision) {
+	v.recordNotification(n)
+	v.Refresher.Notice(n)
+	if n.Missed {
+		v.noteMissed()
+		v.refreshLive()
+		return
+	}
+	if v.Toasts != nil && decision.Interrupts() {
+		if decision.Action == internal.ActionPriority {
+			v.Toasts.ShowSticky(toastText(n), v.Config.Keys.Dismiss+" to dismiss", tcell.ColorRed)
+		} else {
+			v.Toasts.Show(toastText(n), toastColor(n))
+		}
+	}
+	if v.Alerts != nil {
+		if v.Alerts.Wants(n, decision) {
+			v.Alerts.Notify("CodeK7", toastText(n))
+		} else if decision.Action == internal.ActionPriority {
+			v.Alerts.Bell()
+		}
+	}
+	v.refreshLive()
+}
+
+// missedSettle is how long a catch-up replay must be quiet before it is
+// summed up
+const missedSettle = time.Second
+
+// noteMissed counts notifications replayed after the client was away and
+// sums them up in one toast once the replay has settled. Must run on the
+// UI goroutine.
+func (v *Views) noteMissed() {
+	v.missed++
+	if v.missedTimer != nil {
+		v.missedTimer.Stop()
+	}
+	v.missedTimer = time.AfterFunc(missedSettle, func() {
+		v.Draws.Queue(func() {
+			count := v.missed
+			v.missed, v.missedTimer = 0, nil
+			if count > 0 && v.Toasts != nil {
+				v.Toasts.Show(fmt.Sprintf("📬 %d notification(s) arrived while you were away", count), tcell.ColorYellow)
+			}
+		})
+	})
+}
+
+// refreshHeader redraws the status line above every page
+func (v *Views) refreshHeader() {
+	if v.header == nil {
+		return
+	}
+
+	parts := []string{"📺 CodeK7"}
+	if user := v.State.GetUser(); user != nil {
+		parts = append(parts, "👤 "+tview.Escape(user.Username))
+		if n := v.unreadCount(); n > 0 {
+			parts = append(parts, fmt.Sprintf("[yellow]🔔 %d unread[-]", n))
+		} else {
+			parts = append(parts, "🔔 0 unread")
+		}
+	}
+	parts = append(parts, "🛰️ "+v.grpcStatus())
+	if v.WSManager != nil && v.WSManager.IsActive() {
+		parts = append(parts, "🔌 "+describeConnStatus(v.WSManager.Status()))
+	}
+	if banner := v.offlineBanner(); banner != "" {
+		parts = append(parts, "[red]"+banner+"[-]")
+	}
+	v.header.SetText(" " + strings.Join(parts, " │ "))
+}
+
+// refreshLive redraws the live parts of the current view. Must run on the
+// UI goroutine.
+func (v *Views) refreshLive() {
+	v.refreshHeader()
+	if v.liveUpdate != nil {
+		v.liveUpdate()
+	}
+}
+
+// tickLive calls tick on the UI goroutine periodically, so ages and
+// countdowns keep moving, until the view is left
+func (v *Views) tickLive(viewCtx context.Context, interval time.Duration, tick func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-viewCtx.Done():
+				return
+			case <-ticker.C:
+				v.Draws.Queue(func() {
+					if viewCtx.Err() == nil {
+						tick()
+					}
+				})
+			}
+		}
+	}()
 }
 
 // Login View
 func (v *Views) ShowLoginView() {
+	v.enterView()
 	form := tview.NewForm()
 
 	var username, password string
@@ -45,9 +219,7 @@ func (v *Views) ShowLoginView() {
 			v.processLogin(username, password)
 		}).
 		AddButton("Register", v.showRegisterView).
-		AddButton("Back", func() {
-			v.Pages.SwitchToPage("main")
-		})
+		AddButton("Back", v.showMain)
 
 	form.SetBorder(true).SetTitle("🔑 Login").SetTitleAlign(tview.AlignCenter)
 
@@ -65,26 +237,69 @@ func (v *Views) ShowLoginView() {
 
 // Register View
 func (v *Views) ShowRegisterView() {
-	form := tview.NewForm()
-
-	var username, email, password string
+	v.enterView()
+	form := tview.NewForm().SetItemPadding(0)
+
+	var username, email, password, confirm string
+	submitted := false
+
+	// Not scrollable, so Tab skips these rows
+	usernameErr := tview.NewTextView().SetDynamicColors(true).SetScrollable(false).SetSize(1, 0)
+	emailErr := tview.NewTextView().SetDynamicColors(true).SetScrollable(false).SetSize(1, 0)
+	passwordErr := tview.NewTextView().SetDynamicColors(true).SetScrollable(false).SetSize(1, 0)
+	confirmErr := tview.NewTextView().SetDynamicColors(true).SetScrollable(false).SetSize(1, 0)
+	strength := tview.NewTextView().SetLabel("Strength").SetDynamicColors(true).SetScrollable(false).SetSize(1, 0).
+		SetText(strengthMeter(""))
+
+	// Re-run validation and refresh the inline errors; returns true if valid
+	validate := func() bool {
+		errs := validateRegistration(username, email, password, confirm)
+		usernameErr.SetText(fieldError(errs[fieldUsername]))
+		emailErr.SetText(fieldError(errs[fieldEmail]))
+		passwordErr.SetText(fieldError(errs[fieldPassword]))
+		confirmErr.SetText(fieldError(errs[fieldConfirm]))
+		return len(errs) == 0
+	}
 
 	form.AddInputField("Username", "", 30, nil, func(text string) {
 		username = text
+		if submitted {
+			validate()
+		}
 	}).
+		AddFormItem(usernameErr).
 		AddInputField("Email", "", 30, nil, func(text string) {
 			email = text
+			if submitted {
+				validate()
+			}
 		}).
+		AddFormItem(emailErr).
 		AddPasswordField("Password", "", 30, '*', func(text string) {
 			password = text
+			strength.SetText(strengthMeter(text))
+			if submitted {
+				validate()
+			}
 		}).
+		AddFormItem(strength).
+		AddFormItem(passwordErr).
+		AddPasswordField("Confirm Password", "", 30, '*', func(text string) {
+			confirm = text
+			if submitted {
+				validate()
+			}
+		}).
+		AddFormItem(confirmErr).
 		AddButton("Register", func() {
-			v.processRegister(username, email, password)
+			submitted = true
+			if !validate() {
+				return
+			}
+			v.processRegister(username, email, password, confirm)
 		}).
 		AddButton("Back to Login", v.ShowLoginView).
-		AddButton("Back", func() {
-			v.Pages.SwitchToPage("main")
-		})
+		AddButton("Back", v.showMain)
 
 	form.SetBorder(true).SetTitle("📝 Register").SetTitleAlign(tview.AlignCenter)
 
@@ -93,8 +308,8 @@ func (v *Views) ShowRegisterView() {
 		AddItem(nil, 0, 1, false).
 		AddItem(tview.NewFlex().
 			AddItem(nil, 0, 1, false).
-			AddItem(form, 60, 1, true).
-			AddItem(nil, 0, 1, false), 17, 1, true).
+			AddItem(form, 70, 1, true).
+			AddItem(nil, 0, 1, false), 15, 1, true).
 		AddItem(nil, 0, 1, false)
 
 	v.Pages.AddAndSwitchToPage("register", flex, true)
@@ -111,6 +326,7 @@ func (v *Views) ShowUploadView() {
 		return
 	}
 
+	v.enterView()
 	form := tview.NewForm()
 
 	var filePath, title, description string
@@ -145,7 +361,7 @@ func (v *Views) ShowUploadView() {
 	helpText := tview.NewTextView().
 		SetText("📋 Upload Instructions:\n" +
 			"• Supported formats: MP4, AVI, MOV, MKV\n" +
-			"• Maximum file size: 500MB\n" +
+			fmt.Sprintf("• Maximum file size: %dMB\n", v.Config.Transfer.MaxUploadMB) +
 			"• Processing happens in real-time via Kafka\n" +
 			"• You'll receive notifications when complete\n" +
 			"• Files are chunked for efficient streaming").
@@ -170,105 +386,174 @@ func (v *Views) ShowVideosView() {
 		return
 	}
 
-	// Load videos first
-	v.loadUserVideos()
+	viewCtx := v.enterView()
 
 	table := tview.NewTable().SetBorders(true).SetSelectable(true, false)
-
-	// Headers with bold style
-	table.SetCell(0, 0, tview.NewTableCell("ID").SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
-	table.SetCell(0, 1, tview.NewTableCell("Title").SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
-	table.SetCell(0, 2, tview.NewTableCell("Description").SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
-	table.SetCell(0, 3, tview.NewTableCell("Created").SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
-	table.SetCell(0, 4, tview.NewTableCell("File").SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
-
-	videos := v.State.GetVideos()
-	if len(videos) == 0 {
-		// Show empty state message
-		table.SetCell(1, 0, tview.NewTableCell("No videos found"))
-		table.SetCell(1, 1, tview.NewTableCell("Upload your first video!"))
-		table.SetCell(1, 2, tview.NewTableCell(""))
-		table.SetCell(1, 3, tview.NewTableCell(""))
-		table.SetCell(1, 4, tview.NewTableCell(""))
-	} else {
-		for i, video := range videos {
-			row := i + 1
-			table.SetCell(row, 0, tview.NewTableCell(video.Id))
-			table.SetCell(row, 1, tview.NewTableCell(video.Title))
-
-			desc := video.Description
-			if len(desc) > 30 {
-				desc = desc[:30] + "..."
+	// Show the cached list right away and refresh it in place, keeping the
+	// selected video selected
+	var shown []*proto.VideoMetadataResponse
+	v.liveUpdate = func() {
+		selectedID := ""
+		if row, _ := table.GetSelection(); row >= 1 && row <= len(shown) {
+			selectedID = shown[row-1].Id
+		}
+		shown = v.fillVideosTable(table)
+		for i, video := range shown {
+			if video.Id == selectedID {
+				table.Select(i+1, 0)
 			}
-			table.SetCell(row, 2, tview.NewTableCell(desc))
-			table.SetCell(row, 3, tview.NewTableCell(video.CreatedAt))
-			table.SetCell(row, 4, tview.NewTableCell(video.FileName))
 		}
+		table.SetTitle(v.viewTitle("🎞️  My Videos"))
 	}
+	v.liveUpdate()
+	v.reloadVideos(viewCtx, v.refreshLive)
 
 	table.Select(1, 0).SetFixed(1, 1).SetDoneFunc(func(key tcell.Key) {
 		if key == tcell.KeyEscape {
 			v.ShowDashboardView()
 		}
 	})
+	table.SetSelectedFunc(func(row, _ int) {
+		if row >= 1 && row <= len(shown) {
+			v.ShowVideoDetailView(shown[row-1].Id, v.ShowVideosView)
+		}
+	})
 
-	table.SetBorder(true).SetTitle("🎞️  My Videos").SetTitleAlign(tview.AlignCenter)
+	table.SetBorder(true).SetTitle(v.viewTitle("🎞️  My Videos")).SetTitleAlign(tview.AlignCenter)
 
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(table, 0, 1, true).
 		AddItem(tview.NewTextView().
-			SetText("Press ESC to go back to Dashboard | Use arrow keys to navigate").
+			SetText("Press ESC to go back to Dashboard | Enter for details | Use arrow keys to navigate").
 			SetTextAlign(tview.AlignCenter), 1, 0, false)
 
 	v.Pages.AddAndSwitchToPage("videos", flex, true)
 }
 
-// Notifications View
-func (v *Views) ShowNotificationsView() {
-	if !v.State.IsLoggedIn() {
-		v.showMessage("Please login first!")
-		return
-	}
+// fillVideosTable renders the cached library into the videos table and
+// returns the videos shown, in row order
+func (v *Views) fillVideosTable(table *tview.Table) []*proto.VideoMetadataResponse {
+	table.Clear()
 
-	list := tview.NewList()
-	notifications := v.State.GetNotifications()
+	// Headers with bold style
+	table.SetCell(0, 0, tview.NewTableCell("ID").SetTextColor(v.headerColor()).SetSelectable(false).SetAlign(tview.AlignCenter))
+	table.SetCell(0, 1, tview.NewTableCell("Title").SetTextColor(v.headerColor()).SetSelectable(false).SetAlign(tview.AlignCenter))
+	table.SetCell(0, 2, tview.NewTableCell("Description").SetTextColor(v.headerColor()).SetSelectable(false).SetAlign(tview.AlignCenter))
+	table.SetCell(0, 3, tview.NewTableCell("Created").SetTextColor(v.headerColor()).SetSelectable(false).SetAlign(tview.AlignCenter))
+	table.SetCell(0, 4, tview.NewTableCell("File").SetTextColor(v.headerColor()).SetSelectable(false).SetAlign(tview.AlignCenter))
+	table.SetCell(0, 5, tview.NewTableCell("Status").SetTextColor(v.headerColor()).SetSelectable(false).SetAlign(tview.AlignCenter))
 
-	if len(notifications) == 0 {
-		list.AddItem("No notifications", "Connect to WebSocket to receive notifications", 0, nil)
-	} else {
-		for _, notif := range notifications {
-			title := fmt.Sprintf("[%s] %s", notif.Type, notif.Time)
-			list.AddItem(title, notif.Message, 0, nil)
-		}
+	videos := v.State.GetVideos()
+	if len(videos) == 0 {
+		// Show empty state message
+		table.SetCell(1, 0, tview.NewTableCell("No videos found"))
+		table.SetCell(1, 1, tview.NewTableCell("Upload your first video!"))
+		table.SetCell(1, 2, tview.NewTableCell(""))
+		table.SetCell(1, 3, tview.NewTableCell(""))
+		table.SetCell(1, 4, tview.NewTableCell(""))
+		table.SetCell(1, 5, tview.NewTableCell(""))
+		return nil
 	}
 
-	list.SetBorder(true).SetTitle("📡 Notifications").SetTitleAlign(tview.AlignCenter)
-
-	flex := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(list, 0, 1, true).
-		AddItem(tview.NewTextView().
-			SetText("Press ESC to go back to Dashboard").
-			SetTextAlign(tview.AlignCenter), 1, 0, false)
-
-	list.SetDoneFunc(func() {
-		v.ShowDashboardView()
-	})
+	for i, video := range videos {
+		row := i + 1
+		table.SetCell(row, 0, tview.NewTableCell(video.Id))
+		table.SetCell(row, 1, tview.NewTableCell(video.Title))
 
-	v.Pages.AddAndSwitchToPage("notifications", flex, true)
+		desc := video.Description
+		if len(desc) > 30 {
+			desc = desc[:30] + "..."
+		}
+		table.SetCell(row, 2, tview.NewTableCell(desc))
+		table.SetCell(row, 3, tview.NewTableCell(video.CreatedAt))
+		table.SetCell(row, 4, tview.NewTableCell(video.FileName))
+		table.SetCell(row, 5, tview.NewTableCell(tview.Escape(v.videoStatus(video.Id))))
+	}
+	return videos
 }
 
 // Dashboard View (for logged in users)
 func (v *Views) ShowDashboardView() {
 	if !v.State.IsLoggedIn() {
-		v.Pages.SwitchToPage("main")
+		v.showMain()
 		return
 	}
 
-	// Auto-load user videos when showing dashboard
-	v.loadUserVideos()
+	viewCtx := v.enterView()
+
+	info := tview.NewTextView().SetText(v.dashboardInfo())
+	info.SetBorder(true).SetTitle(v.viewTitle("📊 Dashboard - Real-time Status"))
+
+	v.liveUpdate = func() {
+		info.SetText(v.dashboardInfo())
+		info.SetTitle(v.viewTitle("📊 Dashboard - Real-time Status"))
+	}
+
+	// Auto-load user videos and update the counts in place
+	v.reloadVideos(viewCtx, v.refreshLive)
+	v.tickLive(viewCtx, time.Second, v.refreshLive)
+
+	keys := v.Config.Keys
+	menu := tview.NewList().
+		AddItem("📤 Upload Video", "Upload a new video file", shortcut(keys.Upload), v.ShowUploadView).
+		AddItem("🎞️  My Videos", "Browse and manage your videos", shortcut(keys.Videos), v.ShowVideosView).
+		AddItem("📡 Notifications", "View real-time notifications", shortcut(keys.Notifications), v.ShowNotificationsView).
+		AddItem("📊 Recent Videos", "View your 3 most recent videos", shortcut(keys.Recent), v.ShowRecentVideosView).
+		AddItem("📮 Outbox", "Uploads waiting for the server", shortcut(keys.Outbox), v.ShowOutboxView).
+		AddItem("🔄 Refresh Data", "Reload videos and notifications", shortcut(keys.Refresh), v.refreshData).
+		AddItem("🔌 WebSocket", "Toggle real-time connection", shortcut(keys.WebSocket), v.toggleWebSocket).
+		AddItem("🛰️  Reconnect Server", "Retry the gRPC connection now", shortcut(keys.Reconnect), v.reconnectServer).
+		AddItem("🏠 Main Menu", "Return to main menu", shortcut(keys.MainMenu), v.showMain).
+		AddItem("🚪 Logout", "End session and logout", shortcut(keys.Logout), v.handleLogout).
+		SetBorder(true).
+		SetTitle("🎯 Quick Actions")
+
+	// Create main layout
+	flex := tview.NewFlex().
+		AddItem(info, 0, 2, false).
+		AddItem(menu, 0, 1, true)
 
+	// Enhanced keyboard shortcuts
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case config.MatchKey(keys.Upload, event):
+			v.ShowUploadView()
+		case config.MatchKey(keys.Videos, event):
+			v.ShowVideosView()
+		case config.MatchKey(keys.Notifications, event):
+			v.ShowNotificationsView()
+		case config.MatchKey(keys.Recent, event):
+			v.ShowRecentVideosView()
+		case config.MatchKey(keys.Outbox, event):
+			v.ShowOutboxView()
+		case config.MatchKey(keys.Refresh, event):
+			v.refreshData()
+		case config.MatchKey(keys.WebSocket, event):
+			v.toggleWebSocket()
+		case config.MatchKey(keys.Reconnect, event):
+			v.reconnectServer()
+		case config.MatchKey(keys.MainMenu, event):
+			v.showMain()
+		case config.MatchKey(keys.Logout, event):
+			v.handleLogout()
+		case config.MatchKey(keys.Quit, event):
+			v.App.Stop()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	v.Pages.AddAndSwitchToPage("dashboard", flex, true)
+	// Background refreshes must not move focus off the lock screen
+	if !v.State.IsLocked() {
+		v.App.SetFocus(menu)
+	}
+}
+
+// dashboardInfo renders the dashboard status panel from the current state
+func (v *Views) dashboardInfo() string {
 	user := v.State.GetUser()
 	videos := v.State.GetVideos()
 	notifications := v.State.GetNotifications()
@@ -291,91 +576,48 @@ func (v *Views) ShowDashboardView() {
 	}
 
 	wsStatus := "❌ Disconnected"
-	if v.WSManager != nil && v.WSManager.IsConnected() {
-		wsStatus = "✅ Connected"
+	lastMessage := "never"
+	if v.WSManager != nil {
+		status := v.WSManager.Status()
+		wsStatus = describeConnStatus(status)
+		lastMessage = describeAge(status.LastMessage)
 	}
 
-	// Create info panel
-	infoText := fmt.Sprintf(
-		"🎉 Welcome back, %s!\n\n"+
+	banner := v.offlineBanner()
+	if banner != "" {
+		banner += " (read-only)\n\n"
+	}
+
+	return fmt.Sprintf(
+		"%s🎉 Welcome back, %s!\n\n"+
 			"👤 User ID: %s\n"+
 			"🎥 Total Videos: %d\n"+
 			"📺 %s\n"+
-			"📡 Notifications: %d\n"+
-			"🔌 WebSocket: %s\n\n"+
+			"📡 Notifications: %d (%d unread)\n"+
+			"📮 Outbox: %d queued\n"+
+			"🛰️ Server: %s\n"+
+			"🔌 WebSocket: %s\n"+
+			"🕒 Last message: %s\n\n"+
 			"━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"+
 			"💡 Navigation Tips:\n"+
 			"   • Use arrow keys or hotkeys\n"+
 			"   • Press TAB to switch focus\n"+
 			"   • ESC to go back from any view\n"+
+			"   • %s to lock the session\n"+
 			"━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━",
+		banner,
 		username,
 		userID,
 		len(videos),
 		recentVideoText,
 		len(notifications),
-		wsStatus)
-
-	info := tview.NewTextView().
-		SetText(infoText).
-		SetBorder(true).
-		SetTitle("📊 Dashboard - Real-time Status")
+		v.unreadCount(),
+		v.Outbox.Len(),
+		v.grpcStatus(),
+		wsStatus,
+		lastMessage,
+		v.Config.Keys.Lock)
 
-	menu := tview.NewList().
-		AddItem("📤 Upload Video", "Upload a new video file", 'u', v.ShowUploadView).
-		AddItem("🎞️  My Videos", "Browse and manage your videos", 'v', v.ShowVideosView).
-		AddItem("📡 Notifications", "View real-time notifications", 'n', v.ShowNotificationsView).
-		AddItem("📊 Recent Videos", "View your 3 most recent videos", 's', v.ShowRecentVideosView).
-		AddItem("🔄 Refresh Data", "Reload videos and notifications", 'r', v.refreshData).
-		AddItem("🔌 WebSocket", "Toggle real-time connection", 'w', v.toggleWebSocket).
-		AddItem("🏠 Main Menu", "Return to main menu", 'm', func() {
-			v.Pages.SwitchToPage("main")
-		}).
-		AddItem("🚪 Logout", "End session and logout", 'l', v.handleLogout).
-		SetBorder(true).
-		SetTitle("🎯 Quick Actions")
-
-	// Create main layout
-	flex := tview.NewFlex().
-		AddItem(info, 0, 2, false).
-		AddItem(menu, 0, 1, true)
-
-	// Enhanced keyboard shortcuts
-	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Rune() {
-		case 'u':
-			v.ShowUploadView()
-			return nil
-		case 'v':
-			v.ShowVideosView()
-			return nil
-		case 'n':
-			v.ShowNotificationsView()
-			return nil
-		case 's':
-			v.ShowRecentVideosView()
-			return nil
-		case 'r':
-			v.refreshData()
-			return nil
-		case 'w':
-			v.toggleWebSocket()
-			return nil
-		case 'm':
-			v.Pages.SwitchToPage("main")
-			return nil
-		case 'l':
-			v.handleLogout()
-			return nil
-		case 'q':
-			v.App.Stop()
-			return nil
-		}
-		return event
-	})
-
-	v.Pages.AddAndSwitchToPage("dashboard", flex, true)
-	v.App.SetFocus(menu)
 }
 
 // Recent Videos View - shows last 3 videos
@@ -392,48 +634,51 @@ func (v *Views) ShowRecentVideosView() {
 		return
 	}
 
-	// Try to get recent videos via gRPC
-	recentVideos, err := client.GetLast3UserVideos(context.TODO(), &proto.GetLast3UserVideosRequest{
-		UserId: user.Id,
-	})
+	viewCtx := v.enterView()
+	timeout := v.Config.Timeouts.RecentVideos
 
-	var videos []*proto.VideoMetadataResponse
-	if err != nil {
-		// Fallback to local state if gRPC fails
-		allVideos := v.State.GetVideos()
-		if len(allVideos) > 3 {
-			videos = allVideos[:3]
-		} else {
-			videos = allVideos
+	table := tview.NewTable().SetBorders(true).SetSelectable(true, false)
+	v.fillRecentTable(table, nil, true)
+
+	offline, _ := v.State.OfflineSince()
+	go func() {
+		if offline {
+			// Serve the cached library without waiting on the server
+			v.Draws.Queue(func() {
+				if viewCtx.Err() == nil {
+					v.fillRecentTable(table, v.cachedRecentVideos(), false)
+				}
+			})
+			return
 		}
-	} else {
-		videos = recentVideos.Videos
-	}
 
-	table := tview.NewTable().SetBorders(true).SetSelectable(true, false)
+		ctx, cancel := rpcContext(viewCtx, timeout)
+		defer cancel()
 
-	// Headers
-	table.SetCell(0, 0, tview.NewTableCell("Title").SetTextColor(tcell.ColorYellow).SetSelectable(false))
-	table.SetCell(0, 1, tview.NewTableCell("Description").SetTextColor(tcell.ColorYellow).SetSelectable(false))
-	table.SetCell(0, 2, tview.NewTableCell("Created").SetTextColor(tcell.ColorYellow).SetSelectable(false))
+		// Try to get recent videos via gRPC
+		recentVideos, err := client.GetLast3UserVideos(ctx, &proto.GetLast3UserVideosRequest{
+			UserId: user.Id,
+		})
 
-	if len(videos) == 0 {
-		table.SetCell(1, 0, tview.NewTableCell("No recent videos"))
-		table.SetCell(1, 1, tview.NewTableCell("Upload your first video!"))
-		table.SetCell(1, 2, tview.NewTableCell(""))
-	} else {
-		for i, video := range videos {
-			row := i + 1
-			table.SetCell(row, 0, tview.NewTableCell(video.Title))
+		v.Draws.Queue(func() {
+			if viewCtx.Err() != nil {
+				return // user left the view
+			}
 
-			desc := video.Description
-			if len(desc) > 40 {
-				desc = desc[:40] + "..."
+			var videos []*proto.VideoMetadataResponse
+			if err != nil {
+				// Fallback to local state if gRPC fails
+				videos = v.cachedRecentVideos()
+				if isTimeout(err) {
+					v.showRPCError("Loading recent videos", timeout, err)
+				}
+			} else {
+				videos = recentVideos.Videos
+				v.State.SetRecentVideos(videos)
 			}
-			table.SetCell(row, 1, tview.NewTableCell(desc))
-			table.SetCell(row, 2, tview.NewTableCell(video.CreatedAt))
-		}
-	}
+			v.fillRecentTable(table, videos, false)
+		})
+	}()
 
 	table.Select(1, 0).SetFixed(1, 1).SetDoneFunc(func(key tcell.Key) {
 		if key == tcell.KeyEscape {
@@ -441,7 +686,7 @@ func (v *Views) ShowRecentVideosView() {
 		}
 	})
 
-	table.SetBorder(true).SetTitle("📊 Recent Videos (Last 3)").SetTitleAlign(tview.AlignCenter)
+	table.SetBorder(true).SetTitle(v.viewTitle("📊 Recent Videos (Last 3)")).SetTitleAlign(tview.AlignCenter)
 
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
@@ -453,6 +698,59 @@ func (v *Views) ShowRecentVideosView() {
 	v.Pages.AddAndSwitchToPage("recent", flex, true)
 }
 
+// cachedRecentVideos returns the recent videos last fetched, or the start
+// of the library if they never were
+func (v *Views) cachedRecentVideos() []*proto.VideoMetadataResponse {
+	if recent := v.State.GetRecentVideos(); recent != nil {
+		return recent
+	}
+	return firstVideos(v.State.GetVideos(), 3)
+}
+
+// firstVideos returns at most n videos from the start of the list
+func firstVideos(videos []*proto.VideoMetadataResponse, n int) []*proto.VideoMetadataResponse {
+	if len(videos) > n {
+		return videos[:n]
+	}
+	return videos
+}
+
+// fillRecentTable renders up to three recent videos, or a loading row
+func (v *Views) fillRecentTable(table *tview.Table, videos []*proto.VideoMetadataResponse, loading bool) {
+	table.Clear()
+
+	// Headers
+	table.SetCell(0, 0, tview.NewTableCell("Title").SetTextColor(v.headerColor()).SetSelectable(false))
+	table.SetCell(0, 1, tview.NewTableCell("Description").SetTextColor(v.headerColor()).SetSelectable(false))
+	table.SetCell(0, 2, tview.NewTableCell("Created").SetTextColor(v.headerColor()).SetSelectable(false))
+
+	if loading {
+		table.SetCell(1, 0, tview.NewTableCell("⏳ Loading..."))
+		table.SetCell(1, 1, tview.NewTableCell(""))
+		table.SetCell(1, 2, tview.NewTableCell(""))
+		return
+	}
+
+	if len(videos) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("No recent videos"))
+		table.SetCell(1, 1, tview.NewTableCell("Upload your first video!"))
+		table.SetCell(1, 2, tview.NewTableCell(""))
+		return
+	}
+
+	for i, video := range videos {
+		row := i + 1
+		table.SetCell(row, 0, tview.NewTableCell(video.Title))
+
+		desc := video.Description
+		if len(desc) > 40 {
+			desc = desc[:40] + "..."
+		}
+		table.SetCell(row, 1, tview.NewTableCell(desc))
+		table.SetCell(row, 2, tview.NewTableCell(video.CreatedAt))
+	}
+}
+
 // Toggle WebSocket connection
 func (v *Views) toggleWebSocket() {
 	if v.WSManager == nil {
@@ -460,7 +758,7 @@ func (v *Views) toggleWebSocket() {
 		return
 	}
 
-	if v.WSManager.IsConnected() {
+	if v.WSManager.IsActive() {
 		v.WSManager.Disconnect()
 		v.showMessage("🔌 WebSocket disconnected")
 	} else {
@@ -525,37 +823,59 @@ func (v *Views) refreshData() {
 
 	// Show loading message
 	v.showMessage("🔄 Refreshing data...")
+	viewCtx := v.currentView()
 
 	go func() {
 		// Load user videos
-		v.loadUserVideos()
+		loadErr := v.loadUserVideos(viewCtx)
 
 		// Try to fetch recent videos
 		user := v.State.GetUser()
 		client := v.State.GetGRPCClient()
 
+		var recentErr error
 		if client != nil && user != nil {
+			ctx, cancel := rpcContext(viewCtx, v.Config.Timeouts.RecentVideos)
 			// Fetch recent videos asynchronously
-			recentVideos, err := client.GetLast3UserVideos(context.TODO(), &proto.GetLast3UserVideosRequest{
+			recentVideos, err := client.GetLast3UserVideos(ctx, &proto.GetLast3UserVideosRequest{
 				UserId: user.Id,
 			})
+			cancel()
 
-			if err == nil && len(recentVideos.Videos) > 0 {
-				// Update local state with fresh data
-				v.State.SetVideos(recentVideos.Videos)
+			if err == nil {
+				// Kept apart, so the library stays whole
+				v.State.SetRecentVideos(recentVideos.Videos)
 			}
+			recentErr = err
 		}
 
 		// Update UI on main thread
-		v.App.QueueUpdateDraw(func() {
+		v.Draws.Queue(func() {
+			if viewCtx.Err() != nil {
+				return // user left the dashboard
+			}
 			v.Pages.RemovePage("message")
+			if isTimeout(loadErr) {
+				v.showRPCError("Loading videos", v.Config.Timeouts.ListVideos, loadErr)
+				return
+			}
+			if isTimeout(recentErr) {
+				v.showRPCError("Loading recent videos", v.Config.Timeouts.RecentVideos, recentErr)
+				return
+			}
 			v.showMessage("✅ Data refreshed! Updated videos and notifications.")
 
-			// Auto-refresh dashboard after a short delay
-			time.Sleep(1 * time.Second)
-			v.App.QueueUpdateDraw(func() {
-				v.Pages.RemovePage("message")
-				v.ShowDashboardView()
+			v.refreshLive()
+
+			// Dismiss after a short delay, without holding up the UI
+			// goroutine; the view already shows the fresh data
+			time.AfterFunc(time.Second, func() {
+				v.Draws.Queue(func() {
+					if viewCtx.Err() != nil {
+						return
+					}
+					v.Pages.RemovePage("message")
+				})
 			})
 		})
 	}()
@@ -576,13 +896,27 @@ func (v *Views) showError(err error) {
 	v.showMessage(fmt.Sprintf("Error: %v", err))
 }
 
+// shortcut returns the list shortcut rune for a key binding, or 0 when the
+// binding is not a plain character
+func shortcut(binding string) rune {
+	key, r, err := config.ParseKey(binding)
+	if err != nil || key != tcell.KeyRune {
+		return 0
+	}
+	return r
+}
+
 func (v *Views) startWebSocket() {
 	if !v.State.IsLoggedIn() {
 		v.showMessage("Please login first!")
 		return
 	}
+	if v.State.IsReadOnly() {
+		v.showMessage("📴 This offline library is read-only. Log in to connect.")
+		return
+	}
 
-	if v.WSManager != nil && v.WSManager.IsConnected() {
+	if v.WSManager != nil && v.WSManager.IsActive() {
 		v.showMessage("WebSocket already connected!")
 		return
 	}
@@ -594,9 +928,10 @@ func (v *Views) startWebSocket() {
 	}
 
 	if v.WSManager == nil {
-		v.WSManager = NewWebSocketManager(v.State, v.App)
+		v.showMessage("WebSocket manager not initialized!")
+		return
 	}
 
-	go v.WSManager.Connect(user.Id)
+	v.WSManager.Connect(user.Id)
 	v.showMessage("Connecting to WebSocket...")
 }
@@ -1,123 +1,249 @@
 package tui
 
 import (
-	"log"
-	"net/url"
+	"errors"
+	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
-	"github.com/rivo/tview"
+	"github.com/codek7-services/codek7-tui/internal"
+)
+
+const (
+	maxRawFrame = 4096 // bounds how much of a malformed frame is kept
+	recentIDs   = 1000 // event IDs remembered to drop duplicates
 )
 
 type WebSocketManager struct {
-	conn      *websocket.Conn
-	state     *AppState
-	app       *tview.Application
-	connected bool
+	client  *internal.NotifierClient
+	state   *AppState
+	draws   *DrawQueue
+	cursors *internal.CursorStore // nil disables catch-up
+
 	mu        sync.RWMutex
-	stopCh    chan struct{}
+	onChange  func(internal.ConnStatus)
+	onMessage func(Notification)
+	userID    string
+	cursor    internal.Cursor
+	seen      *internal.RecentIDs
+	stops     int // Disconnect calls; updates queued before one are dropped
 }
 
-func NewWebSocketManager(state *AppState, app *tview.Application) *WebSocketManager {
-	return &WebSocketManager{
-		state:  state,
-		app:    app,
-		stopCh: make(chan struct{}),
+// NewWebSocketManager creates the manager; with cursors set, the last
+// event seen is remembered per user and events sent while the client was
+// away are asked for on every connect
+func NewWebSocketManager(state *AppState, draws *DrawQueue, opts internal.NotifierOptions, cursors *internal.CursorStore) *WebSocketManager {
+	wsm := &WebSocketManager{
+		state:   state,
+		draws:   draws,
+		cursors: cursors,
+		seen:    internal.NewRecentIDs(recentIDs),
 	}
+	wsm.client = internal.NewNotifierClient(opts, internal.NotifierHandlers{
+		OnState:   wsm.handleState,
+		OnMessage: wsm.handleMessage,
+		Since:     wsm.since,
+	})
+	return wsm
 }
 
-func (wsm *WebSocketManager) Connect(userID string) {
+// SetStateHandler registers a callback for connection state changes; it
+// runs on the UI goroutine
+func (wsm *WebSocketManager) SetStateHandler(fn func(internal.ConnStatus)) {
 	wsm.mu.Lock()
 	defer wsm.mu.Unlock()
+	wsm.onChange = fn
+}
 
-	if wsm.connected {
-		return
-	}
+// SetMessageHandler registers a callback for each notification received;
+// it runs on the UI goroutine and is responsible for storing it
+func (wsm *WebSocketManager) SetMessageHandler(fn func(Notification)) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.onMessage = fn
+}
 
-	wsURL := "ws://localhost:8080/ws/" + userID
-	u, err := url.Parse(wsURL)
-	if err != nil {
-		log.Printf("WebSocket URL parse error: %v", err)
-		return
+// Connect starts a supervised session that reconnects until Disconnect.
+// Server notifications already in the state count as seen, so a replay
+// does not repeat them. The cursor only moves with events received from
+// the notifier: local notifications carry IDs and clock times the server
+// does not know.
+func (wsm *WebSocketManager) Connect(userID string) {
+	wsm.mu.Lock()
+	if wsm.userID != userID {
+		wsm.userID = userID
+		wsm.cursor = internal.Cursor{}
+		wsm.seen = internal.NewRecentIDs(recentIDs)
+		if wsm.cursors != nil {
+			cursor, err := wsm.cursors.Load(userID)
+			if err != nil {
+				slog.Warn("Loading notification cursor failed", "err", err)
+			}
+			wsm.cursor = cursor
+		}
 	}
-
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
-	if err != nil {
-		log.Printf("WebSocket connection error: %v", err)
-		return
+	for _, n := range wsm.state.GetNotifications() {
+		if !n.Malformed() && !n.Local() {
+			wsm.seen.Seen(n.Event.ID)
+		}
 	}
+	wsm.mu.Unlock()
 
-	wsm.conn = conn
-	wsm.connected = true
+	wsm.client.Start(userID)
+}
 
-	go wsm.readMessages()
+// since returns the catch-up cursor for the next connect
+func (wsm *WebSocketManager) since() string {
+	if wsm.cursors == nil {
+		return ""
+	}
+	wsm.mu.RLock()
+	defer wsm.mu.RUnlock()
+	return wsm.cursor.Since()
 }
 
-func (wsm *WebSocketManager) readMessages() {
-	defer func() {
-		wsm.mu.Lock()
-		if wsm.conn != nil {
-			wsm.conn.Close()
-		}
-		wsm.connected = false
+// track drops events already seen and moves the cursor past new ones. It
+// reports whether the event is new.
+func (wsm *WebSocketManager) track(e internal.Event) bool {
+	wsm.mu.Lock()
+	if wsm.seen.Seen(e.ID) {
 		wsm.mu.Unlock()
-	}()
+		return false
+	}
+	if !wsm.cursor.Advance(e) || wsm.cursors == nil {
+		wsm.mu.Unlock()
+		return true
+	}
+	userID, cursor := wsm.userID, wsm.cursor
+	wsm.mu.Unlock()
 
-	for {
-		select {
-		case <-wsm.stopCh:
-			return
-		default:
-			var msg map[string]interface{}
-			err := wsm.conn.ReadJSON(&msg)
-			if err != nil {
-				log.Printf("WebSocket read error: %v", err)
-				return
-			}
+	if err := wsm.cursors.Save(userID, cursor); err != nil {
+		slog.Warn("Saving notification cursor failed", "err", err)
+	}
+	return true
+}
 
-			// Convert to notification
-			notif := Notification{
-				ID:      time.Now().Format("20060102150405"),
-				Type:    "notification",
-				Message: "New notification received",
-				Time:    time.Now().Format("15:04:05"),
-			}
+func (wsm *WebSocketManager) handleState(status internal.ConnStatus) {
+	if status.State == internal.StateBackoff && status.Err != nil {
+		slog.Warn("WebSocket connection lost", "attempt", status.Attempt, "err", status.Err)
+	}
 
-			if msgType, ok := msg["type"].(string); ok {
-				notif.Type = msgType
-			}
-			if message, ok := msg["message"].(string); ok {
-				notif.Message = message
+	wsm.mu.RLock()
+	fn, stops := wsm.onChange, wsm.stops
+	wsm.mu.RUnlock()
+	if fn != nil {
+		wsm.draws.Queue(func() {
+			if wsm.stopped(stops) {
+				return
 			}
+			fn(status)
+		})
+	}
+}
 
-			wsm.state.AddNotification(notif)
-
-			// Update UI
-			wsm.app.QueueUpdateDraw(func() {
-				log.Printf("🔔 Notification: %s - %s", notif.Type, notif.Message)
-			})
+func (wsm *WebSocketManager) handleMessage(data []byte) {
+	notif := Notification{Received: time.Now()}
+	event, err := internal.DecodeEvent(data)
+	if err != nil {
+		// Keep the frame so it can be inspected, but not all of a huge one
+		raw := string(data)
+		if len(raw) > maxRawFrame {
+			raw = raw[:maxRawFrame] + "..."
+		}
+		notif.Raw = raw
+		notif.Error = err.Error()
+		slog.Warn("Malformed notification", "err", err)
+	} else {
+		if !wsm.track(event) {
+			slog.Debug("Dropping duplicate notification", "id", event.ID)
+			return
 		}
+		notif.Event = event
+		notif.Missed = event.Replayed()
+	}
+
+	wsm.mu.RLock()
+	fn, stops := wsm.onMessage, wsm.stops
+	wsm.mu.RUnlock()
+	if fn == nil {
+		wsm.state.AddNotification(notif)
+		return
 	}
+	wsm.draws.Queue(func() {
+		if wsm.stopped(stops) {
+			return
+		}
+		fn(notif)
+	})
 }
 
-func (wsm *WebSocketManager) IsConnected() bool {
+// stopped reports whether the session was disconnected after an update
+// was queued, so it no longer applies
+func (wsm *WebSocketManager) stopped(stops int) bool {
 	wsm.mu.RLock()
 	defer wsm.mu.RUnlock()
-	return wsm.connected
+	return wsm.stops != stops
+}
+
+func (wsm *WebSocketManager) Status() internal.ConnStatus {
+	return wsm.client.Status()
+}
+
+func (wsm *WebSocketManager) IsConnected() bool {
+	return wsm.client.Status().State == internal.StateConnected
+}
+
+// IsActive reports whether a session is running, connected or not
+func (wsm *WebSocketManager) IsActive() bool {
+	return wsm.client.Status().State != internal.StateStopped
 }
 
+// Disconnect stops the session without waiting for it, so it can run on
+// the UI goroutine while the session is queuing an update
 func (wsm *WebSocketManager) Disconnect() {
 	wsm.mu.Lock()
-	defer wsm.mu.Unlock()
+	wsm.stops++
+	wsm.mu.Unlock()
+	wsm.client.Stop()
+}
 
-	if !wsm.connected {
-		return
+// describeConnStatus renders a connection status for the UI
+func describeConnStatus(status internal.ConnStatus) string {
+	switch status.State {
+	case internal.StateConnected:
+		return "✅ Connected"
+	case internal.StateConnecting:
+		if status.Attempt > 0 {
+			return fmt.Sprintf("🔄 Reconnecting (attempt %d)...", status.Attempt+1)
+		}
+		return "🔄 Connecting..."
+	case internal.StateBackoff:
+		wait := time.Until(status.RetryAt).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		if errors.Is(status.Err, internal.ErrStale) {
+			return fmt.Sprintf("⚠️ Stale, retrying in %s (attempt %d)", wait, status.Attempt)
+		}
+		return fmt.Sprintf("⏳ Retrying in %s (attempt %d)", wait, status.Attempt)
 	}
+	return "❌ Disconnected"
+}
 
-	close(wsm.stopCh)
-	if wsm.conn != nil {
-		wsm.conn.Close()
+// describeAge renders how long ago t was, for status lines
+func describeAge(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	age := time.Since(t)
+	switch {
+	case age < time.Second:
+		return "just now"
+	case age < time.Minute:
+		return fmt.Sprintf("%ds ago", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
 	}
-	wsm.connected = false
+	return t.Format("15:04:05")
 }
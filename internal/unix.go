@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// UnixSocketPath returns the socket path of a unix:///abs/path or
+// unix:relative/path address
+func UnixSocketPath(addr string) (string, bool) {
+	if rest, ok := strings.CutPrefix(addr, "unix://"); ok {
+		return rest, rest != ""
+	}
+	if rest, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return rest, rest != ""
+	}
+	return "", false
+}
+
+// DialUnix connects to a Unix socket, explaining the usual failures
+func DialUnix(ctx context.Context, path string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err == nil {
+		return conn, nil
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("socket %s does not exist; is the service running and the volume mounted?", path)
+	case errors.Is(err, fs.ErrPermission):
+		return nil, fmt.Errorf("permission denied on socket %s%s; your user needs write access to it and search access to its directories",
+			path, describeOwner(path))
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return nil, fmt.Errorf("nothing is listening on socket %s; the file may be left over from a stopped service", path)
+	}
+	return nil, fmt.Errorf("connect to socket %s: %w", path, err)
+}
+
+// describeOwner renders the mode of a socket for error messages
+func describeOwner(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (mode %s)", info.Mode().Perm())
+}
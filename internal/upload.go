@@ -8,8 +8,8 @@ import (
 	proto "github.com/codek7-services/codek7-tui/pkg/pb"
 )
 
-func UploadVideo(client proto.RepoServiceClient, filePath, title, description, userID string) error {
-	stream, err := client.UploadVideo(context.TODO())
+func UploadVideo(ctx context.Context, client proto.RepoServiceClient, filePath, title, description, userID string, chunkSize int) error {
+	stream, err := client.UploadVideo(ctx)
 	if err != nil {
 		return err
 	}
@@ -36,7 +36,7 @@ func UploadVideo(client proto.RepoServiceClient, filePath, title, description, u
 	}
 	defer file.Close()
 
-	buf := make([]byte, 1024*64)
+	buf := make([]byte, chunkSize)
 	for {
 		n, err := file.Read(buf)
 		if err == io.EOF {